@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/tsaikd/KDGoLib/cliutil/cmder"
+	"github.com/tsaikd/KDGoLib/errutil"
+	"github.com/tsaikd/go-raml-parser/parser"
+	"gopkg.in/urfave/cli.v2"
+)
+
+// Module info
+var Module = cmder.NewModule("cache").
+	SetUsage("Maintain RAML parser cache directory, command: stats|prune|clear").
+	AddFlag(
+		&cli.StringFlag{
+			Name:        "d",
+			Aliases:     []string{"cacheDirectory"},
+			Value:       ".cache",
+			Usage:       "RAML parser cache directory",
+			Destination: &cacheDirectory,
+		},
+		&cli.Int64Flag{
+			Name:        "maxBytes",
+			Usage:       "Max total bytes of cache entries kept by prune, required to be positive, least recently used entries are evicted",
+			Destination: &maxBytes,
+		},
+	).
+	SetAction(action)
+
+var cacheDirectory string
+var maxBytes int64
+
+// errors
+var (
+	ErrorUnknownCacheCommand1 = errutil.NewFactory("unknown cache command %q, should be one of stats, prune, clear")
+	ErrorInvalidMaxBytes1     = errutil.NewFactory("maxBytes of prune should be positive, but got %d")
+)
+
+func action(c *cli.Context) (err error) {
+	return run(os.Stdout, c.Args().First(), cacheDirectory, maxBytes)
+}
+
+// run the cache command on cache directory and print the stats after command
+func run(w io.Writer, command string, cacheDirectory string, maxBytes int64) (err error) {
+	cache := parser.NewFSCache(cacheDirectory, 0)
+
+	switch command {
+	case "stats":
+	case "prune":
+		// prune with 0 bytes removes all entries, use clear instead
+		if maxBytes < 1 {
+			return ErrorInvalidMaxBytes1.New(nil, maxBytes)
+		}
+		if err = cache.Prune(maxBytes); err != nil {
+			return
+		}
+	case "clear":
+		if err = cache.Clear(); err != nil {
+			return
+		}
+	default:
+		return ErrorUnknownCacheCommand1.New(nil, command)
+	}
+
+	stats, err := cache.Stats()
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "entries: %d\nbytes: %d\n", stats.Entries, stats.Bytes)
+	return
+}
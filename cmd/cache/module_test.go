@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tsaikd/go-raml-parser/parser"
+)
+
+func Test_Run(t *testing.T) {
+	require := require.New(t)
+	require.NotNil(require)
+
+	tempdir, err := ioutil.TempDir("", "go-raml-parser")
+	require.NoError(err)
+	defer os.RemoveAll(tempdir)
+
+	cache := parser.NewFSCache(tempdir, 0)
+	require.NoError(cache.Set("a", []byte("aaaa")))
+	require.NoError(cache.Set("b", []byte("bbbb")))
+
+	output := &bytes.Buffer{}
+	require.NoError(run(output, "stats", tempdir, 0))
+	require.Equal("entries: 2\nbytes: 8\n", output.String())
+
+	// prune without maxBytes should not remove all entries
+	output.Reset()
+	err = run(output, "prune", tempdir, 0)
+	require.True(ErrorInvalidMaxBytes1.Match(err))
+	err = run(output, "prune", tempdir, -1)
+	require.True(ErrorInvalidMaxBytes1.Match(err))
+	require.Empty(output.String())
+	stats, err := cache.Stats()
+	require.NoError(err)
+	require.Equal(parser.CacheStats{Entries: 2, Bytes: 8}, stats)
+
+	require.NoError(run(output, "prune", tempdir, 6))
+	require.Equal("entries: 1\nbytes: 4\n", output.String())
+
+	output.Reset()
+	require.NoError(run(output, "clear", tempdir, 0))
+	require.Equal("entries: 0\nbytes: 0\n", output.String())
+
+	err = run(output, "unknown", tempdir, 0)
+	require.True(ErrorUnknownCacheCommand1.Match(err))
+}
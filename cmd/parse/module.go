@@ -2,6 +2,9 @@ package parse
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
 
 	"github.com/tsaikd/KDGoLib/cliutil/cmder"
 	"github.com/tsaikd/KDGoLib/jsonex"
@@ -21,6 +24,16 @@ var Module = cmder.NewModule("parse").
 			Usage:       "Source RAML file",
 			Destination: &ramlFile,
 		},
+		&cli.StringFlag{
+			Name:        "cacheDirectory",
+			Usage:       "RAML parser cache directory, disable cache if empty",
+			Destination: &cacheDirectory,
+		},
+		&cli.Int64Flag{
+			Name:        "cacheMaxBytes",
+			Usage:       "Max total bytes of cache entries, least recently used entries are evicted",
+			Destination: &cacheMaxBytes,
+		},
 		&cli.BoolFlag{
 			Name:        "checkRAMLVersion",
 			Usage:       "Check RAML Version",
@@ -36,6 +49,32 @@ var Module = cmder.NewModule("parse").
 			Usage:       "Ignore unused traits",
 			Destination: &ignoreUnusedTrait,
 		},
+		&cli.BoolFlag{
+			Name:        "ignoreUnusedType",
+			Usage:       "Ignore unused types",
+			Value:       true,
+			Destination: &ignoreUnusedType,
+		},
+		&cli.BoolFlag{
+			Name:        "ignoreUnusedLibrary",
+			Usage:       "Ignore used libraries whose members are never referenced",
+			Value:       true,
+			Destination: &ignoreUnusedLibrary,
+		},
+		&cli.StringSliceFlag{
+			Name:  "libraryPath",
+			Usage: "Directory to search library files of uses, searched before $RAML_PATH",
+		},
+		&cli.BoolFlag{
+			Name:        "diagnostics",
+			Usage:       "Show all problems in stderr instead of failing on the first error",
+			Destination: &diagnostics,
+		},
+		&cli.BoolFlag{
+			Name:        "showLibraries",
+			Usage:       "Show resolved file of each used library in stderr",
+			Destination: &showLibraries,
+		},
 		&cli.BoolFlag{
 			Name:        "allowIntegerToBeNumber",
 			Usage:       "Allow integer type to be number type when checking",
@@ -51,20 +90,38 @@ var Module = cmder.NewModule("parse").
 			Usage:       "Allow required property to be empty value, but still should be existed",
 			Destination: &allowRequiredPropertyToBeEmpty,
 		},
+		&cli.BoolFlag{
+			Name:        "allValueFailures",
+			Usage:       "Show all failures of checking example value instead of the first failure",
+			Destination: &allValueFailures,
+		},
 	).
 	SetAction(action)
 
 var ramlFile string
+var cacheDirectory string
+var cacheMaxBytes int64
 var checkRAMLVersion bool
 var ignoreUnusedAnnotation bool
 var ignoreUnusedTrait bool
+var ignoreUnusedType bool
+var ignoreUnusedLibrary bool
+var diagnostics bool
+var showLibraries bool
 var allowIntegerToBeNumber bool
 var allowArrayToBeNull bool
 var allowRequiredPropertyToBeEmpty bool
+var allValueFailures bool
 
 func action(c *cli.Context) (err error) {
 	ramlParser := parser.NewParser()
 
+	if err = ramlParser.Config(parserConfig.CacheDirectory, cacheDirectory); err != nil {
+		return
+	}
+	if err = ramlParser.Config(parserConfig.CacheMaxBytes, cacheMaxBytes); err != nil {
+		return
+	}
 	if err = ramlParser.Config(parserConfig.CheckRAMLVersion, checkRAMLVersion); err != nil {
 		return
 	}
@@ -74,21 +131,56 @@ func action(c *cli.Context) (err error) {
 	if err = ramlParser.Config(parserConfig.IgnoreUnusedTrait, ignoreUnusedTrait); err != nil {
 		return
 	}
+	if err = ramlParser.Config(parserConfig.IgnoreUnusedType, ignoreUnusedType); err != nil {
+		return
+	}
+	if err = ramlParser.Config(parserConfig.IgnoreUnusedLibrary, ignoreUnusedLibrary); err != nil {
+		return
+	}
+
+	// RAML_PATH is a list of directories separated by OS path list separator
+	searchPaths := append(c.StringSlice("libraryPath"), filepath.SplitList(os.Getenv("RAML_PATH"))...)
+	if err = ramlParser.Config(parserConfig.LibrarySearchPaths, searchPaths); err != nil {
+		return
+	}
 
 	checkOptions := []parser.CheckValueOption{
 		parser.CheckValueOptionAllowIntegerToBeNumber(allowIntegerToBeNumber),
 		parser.CheckValueOptionAllowArrayToBeNull(allowArrayToBeNull),
 		parser.CheckValueOptionAllowRequiredPropertyToBeEmpty(allowRequiredPropertyToBeEmpty),
+		parser.CheckValueOptionAllFailures(allValueFailures),
 	}
 	if err = ramlParser.Config(parserConfig.CheckValueOptions, checkOptions); err != nil {
 		return
 	}
 
+	collector := parser.NewDiagnosticCollector()
+	if diagnostics {
+		if err = ramlParser.Config(parserConfig.DiagnosticCollector, collector); err != nil {
+			return
+		}
+	}
+
 	rootdoc, err := ramlParser.ParseFile(ramlFile)
+	for _, diagnostic := range collector.Diagnostics() {
+		fmt.Fprintln(os.Stderr, diagnostic)
+	}
 	if err != nil {
 		return
 	}
 
+	if showLibraries {
+		locations := rootdoc.LibraryLocations()
+		names := []string{}
+		for name := range locations {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", name, locations[name])
+		}
+	}
+
 	jsondata, err := jsonex.MarshalIndent(rootdoc, "", "  ")
 	if err != nil {
 		return
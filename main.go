@@ -3,6 +3,7 @@ package main
 import (
 	"github.com/tsaikd/KDGoLib/cliutil/cmder"
 	"github.com/tsaikd/go-raml-parser/cmd"
+	"github.com/tsaikd/go-raml-parser/cmd/cache"
 	"github.com/tsaikd/go-raml-parser/cmd/parse"
 )
 
@@ -10,5 +11,6 @@ func main() {
 	cmder.Main(
 		*cmd.Module,
 		*parse.Module,
+		*cache.Module,
 	)
 }
@@ -1,11 +1,10 @@
 package parser
 
 import (
-	"io/ioutil"
-	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
-
-	"github.com/tsaikd/go-raml-parser/parser/parserConfig"
+	"strings"
 )
 
 // APITypes map of APIType
@@ -23,6 +22,47 @@ func (t APITypes) IsEmpty() bool {
 	return true
 }
 
+// UnmarshalYAML unmarshal APITypes from YAML,
+// a type declaration MIGHT be a simple type name, e.g. `Username: string`
+func (t *APITypes) UnmarshalYAML(unmarshaler func(interface{}) error) (err error) {
+	buffer := map[string]*shorthandAPIType{}
+	if err = unmarshaler(&buffer); err != nil {
+		return
+	}
+
+	*t = APITypes{}
+	for name, elem := range buffer {
+		if elem == nil {
+			(*t)[name] = nil
+			continue
+		}
+		(*t)[name] = &elem.APIType
+	}
+	return
+}
+
+// shorthandAPIType wrap APIType for unmarshal type declaration by type name,
+// it is only used for unmarshal YAML
+type shorthandAPIType struct {
+	APIType `json:"-"`
+}
+
+// UnmarshalYAML implement yaml unmarshaler
+func (t *shorthandAPIType) UnmarshalYAML(unmarshaler func(interface{}) error) (err error) {
+	var name string
+	if err = unmarshaler(&name); err == nil {
+		// facets of shorthand declaration are defaults
+		t.APIType = *NewAPIType()
+		t.setType(name)
+		return
+	}
+	if !isErrorYAMLIntoString(err) {
+		return
+	}
+
+	return unmarshaler(&t.APIType)
+}
+
 // NewAPIType return empty APIType
 func NewAPIType() *APIType {
 	apiType := &APIType{}
@@ -37,6 +77,7 @@ type APIType struct {
 	TypeDeclaration
 	ObjectType
 	ScalarType
+	Number
 	String
 	ArrayType
 	FileType
@@ -47,6 +88,14 @@ type APIType struct {
 	NativeType string `yaml:"-" json:"-"`
 	// IsArray means the NativeType is array or not
 	IsArray bool `yaml:"-" json:"-"`
+	// source position of the node
+	Position Position `yaml:"-" json:"-"`
+	// The field used for check typo error in RAML file
+	TypoCheck typoCheck `yaml:"-" json:"-"`
+
+	// refType is the declared type of a recursive reference,
+	// properties are not merged but resolved lazily to avoid cycles
+	refType *APIType
 }
 
 // BeforeUnmarshalYAML implement yaml Initiator
@@ -66,17 +115,46 @@ func (t *APIType) BeforeUnmarshalYAML() (err error) {
 	return
 }
 
+// apiTypeTypoKeys valid facets of type declaration, required is valid because
+// uriParameters are properties declaration but declared by APITypes
+var apiTypeTypoKeys = newTypoKeys(
+	TypeDeclaration{},
+	ObjectType{},
+	ScalarType{},
+	Number{},
+	String{},
+	ArrayType{},
+	FileType{},
+	PropertyExtra{},
+)
+
 // UnmarshalYAML implement yaml unmarshaler
 func (t *APIType) UnmarshalYAML(unmarshaler func(interface{}) error) (err error) {
+	if err = t.unmarshalFacets(unmarshaler); err != nil {
+		return
+	}
+	if t.TypoCheck, err = unmarshalTypoCheck(unmarshaler, apiTypeTypoKeys); err != nil {
+		return
+	}
+	return nil
+}
+
+func (t *APIType) unmarshalFacets(unmarshaler func(interface{}) error) (err error) {
 	if err = unmarshaler(&t.TypeDeclaration); err != nil {
 		return
 	}
 	t.setType(t.TypeDeclaration.Type)
-	if t.IsArray {
+	if t.IsArray || t.Type == TypeArray {
 		if err = unmarshaler(&t.ArrayType); err != nil {
 			return
 		}
 	}
+	if t.Type == TypeArray && t.Items.Type == TypeString {
+		// "type: array" with items declared by type name, e.g. "items: User",
+		// is the same as "User[]" but keep the declared type name
+		t.BaseType, t.IsArray = t.Items.String, true
+		t.NativeType = t.BaseType
+	}
 	if err = unmarshaler(&t.ObjectType); err != nil {
 		return
 	}
@@ -89,6 +167,9 @@ func (t *APIType) UnmarshalYAML(unmarshaler func(interface{}) error) (err error)
 	if err = unmarshaler(&t.ScalarType); err != nil {
 		return
 	}
+	if err = unmarshaler(&t.Number); err != nil {
+		return
+	}
 	if err = unmarshaler(&t.String); err != nil {
 		return
 	}
@@ -103,6 +184,7 @@ func (t APIType) IsEmpty() bool {
 	return t.TypeDeclaration.IsEmpty() &&
 		t.ObjectType.IsEmpty() &&
 		t.ScalarType.IsEmpty() &&
+		t.Number.IsEmpty() &&
 		t.String.IsEmpty() &&
 		t.ArrayType.IsEmpty() &&
 		t.FileType.IsEmpty() &&
@@ -117,6 +199,95 @@ func (t *APIType) setType(name string) {
 	t.NativeType = t.BaseType
 }
 
+var _ checkTypoError = APIType{}
+
+func (t APIType) checkTypoError() (err error) {
+	if !t.TypoCheck.IsEmpty() {
+		return newUnknownFacetError("APIType", t.TypoCheck.Names(), apiTypeTypoKeys)
+	}
+	return t.Properties.checkPropertyTypoError()
+}
+
+var _ checkUnusedType = &APIType{}
+
+// checkUnusedType mark types referenced by apiType as used, types referenced
+// by declared types are marked in afterCheckUnusedType if the declared
+// types are used
+func (t *APIType) checkUnusedType(conf PostProcessConfig) (err error) {
+	if t == nil {
+		return
+	}
+	library := conf.Library()
+	if library.declaredTypes[t] {
+		return
+	}
+	markTypeUsage(*library, conf.TypeUsage(), t)
+	return
+}
+
+// markTypeUsage mark types of library referenced by apiType as used,
+// return names of types which are marked
+func markTypeUsage(library Library, typeUsage map[string]bool, apiType *APIType) (marked []string) {
+	return markTypeNamesUsage(library, typeUsage, apiTypeReferences(apiType))
+}
+
+// markTypeNamesUsage mark types of library in names as used, return names
+// of types which are marked
+func markTypeNamesUsage(library Library, typeUsage map[string]bool, names []string) (marked []string) {
+	prefix := library.Prefix()
+	for _, name := range names {
+		if _, err := library.GetType(name); err != nil {
+			// RAML built-in type or undefined type reported by others
+			continue
+		}
+		if unused, exist := typeUsage[prefix+name]; !exist || unused {
+			typeUsage[prefix+name] = false
+			marked = append(marked, prefix+name)
+		}
+	}
+	return
+}
+
+// apiTypeReferences return type names referenced by apiType and its
+// properties, names MAY be prefixed by library names
+func apiTypeReferences(apiType *APIType) (names []string) {
+	visited := map[*APIType]bool{}
+	var walk func(apiType *APIType)
+	walk = func(apiType *APIType) {
+		if apiType == nil || visited[apiType] {
+			return
+		}
+		visited[apiType] = true
+
+		names = append(names, typeExpressionNames(apiType.Type)...)
+		switch apiType.Items.Type {
+		case TypeString:
+			names = append(names, typeExpressionNames(apiType.Items.String)...)
+		case TypeObject:
+			if items := apiType.Items.Map["type"]; items != nil && items.Type == TypeString {
+				names = append(names, typeExpressionNames(items.String)...)
+			}
+		}
+		for _, property := range apiType.Properties.Slice() {
+			walk(&property.APIType)
+		}
+	}
+	walk(apiType)
+	return
+}
+
+var regexpTypeExpressionName = regexp.MustCompile(`[\w]+(\.[\w]+)*`)
+
+// typeExpressionNames return type names in type expression,
+// e.g. "Person" and "lib.Admin" in "(Person | lib.Admin)[]"
+func typeExpressionNames(expr string) []string {
+	if strings.ContainsAny(expr, "{<") {
+		// declared by JSON or XML schema
+		return nil
+	}
+	return regexpTypeExpressionName.FindAllString(expr, -1)
+}
+
 var _ fillProperties = &APIType{}
 
 func (t *APIType) fillProperties(library Library) (err error) {
@@ -126,7 +297,8 @@ func (t *APIType) fillProperties(library Library) (err error) {
 
 	// fill Properties if possible
 	switch t.BaseType {
-	case "", TypeBoolean, TypeInteger, TypeNumber, TypeString, TypeFile:
+	case "", TypeBoolean, TypeInteger, TypeNumber, TypeString, TypeFile,
+		TypeDateOnly, TypeTimeOnly, TypeDateTimeOnly, TypeDateTime:
 		// no more action for RAML built-in type
 		return
 	case TypeObject:
@@ -135,22 +307,7 @@ func (t *APIType) fillProperties(library Library) (err error) {
 			return
 		}
 
-		for _, property := range t.Properties.Slice() {
-			if err = property.Annotations.fixEmptyAnnotation(); err != nil {
-				return
-			}
-			if err = property.Annotations.fixAnnotationBracket(); err != nil {
-				return
-			}
-			if err = property.Annotations.fillAnnotation(library); err != nil {
-				return
-			}
-			if err = property.APIType.fillProperties(library); err != nil {
-				return
-			}
-		}
-
-		return
+		return t.fillOwnProperties(library, Properties{})
 	default:
 		if isInlineAPIType(*t) {
 			// no more action if declared by JSON
@@ -158,9 +315,28 @@ func (t *APIType) fillProperties(library Library) (err error) {
 		}
 
 		var typ *APIType
-		var exist bool
-		if typ, exist = library.Types[t.BaseType]; !exist {
-			return ErrorTypeUndefined1.New(nil, t.Type)
+		if typ, err = library.GetType(t.BaseType); err != nil {
+			return withPosition(err, t.Position)
+		}
+
+		if err = checkPropertyOverride(library, *t, *typ); err != nil {
+			return withPosition(err, t.Position)
+		}
+
+		t.removeDeclaredFacets(library, *typ)
+
+		if err = t.fillOwnProperties(library, typ.Properties); err != nil {
+			return
+		}
+
+		if isAPITypeReachable(library, *typ, t, map[*APIType]bool{}) {
+			// recursive type reference, keep a lazy reference instead of
+			// merging properties, otherwise the properties form a cycle
+			objectType := t.ObjectType
+			mergeAPIType(t, *typ)
+			t.ObjectType = objectType
+			t.refType = typ
+			return
 		}
 
 		mergeAPIType(t, *typ)
@@ -169,6 +345,89 @@ func (t *APIType) fillProperties(library Library) (err error) {
 	}
 }
 
+// removeDeclaredFacets remove user-defined facets declared by ancestors of
+// apiType from TypoCheck, facet name MAY be suffixed by "?"
+func (t *APIType) removeDeclaredFacets(library Library, parent APIType) {
+	visited := map[string]bool{}
+	for len(t.TypoCheck) > 0 {
+		for name := range parent.Facets.Map {
+			delete(t.TypoCheck, strings.TrimSuffix(name, "?"))
+		}
+		if visited[parent.BaseType] {
+			return
+		}
+		visited[parent.BaseType] = true
+		next, err := library.GetType(parent.BaseType)
+		if err != nil {
+			// RAML built-in type or undefined type reported by others
+			return
+		}
+		parent = *next
+	}
+}
+
+// fillOwnProperties fill properties not inherited from parent, inherited
+// properties are filled with the library declaring them
+func (t *APIType) fillOwnProperties(library Library, parent Properties) (err error) {
+	inherited := parent.Map()
+	for _, property := range t.Properties.Slice() {
+		if inherited[property.Name] == property {
+			continue
+		}
+		for _, annotations := range property.allAnnotations() {
+			if err = annotations.fixEmptyAnnotation(); err != nil {
+				return withPosition(err, property.Position)
+			}
+			if err = annotations.fixAnnotationBracket(); err != nil {
+				return withPosition(err, property.Position)
+			}
+			if err = annotations.fillAnnotation(library); err != nil {
+				return withPosition(err, property.Position)
+			}
+		}
+		if err = property.APIType.fillProperties(library); err != nil {
+			return withPosition(err, property.Position)
+		}
+	}
+	return
+}
+
+// isAPITypeReachable return true if target can be found in properties of
+// apiType, including properties of referenced declared types
+func isAPITypeReachable(library Library, apiType APIType, target *APIType, visited map[*APIType]bool) bool {
+	for _, property := range apiType.resolved().Properties.Slice() {
+		if property == nil || visited[&property.APIType] {
+			continue
+		}
+		if &property.APIType == target {
+			return true
+		}
+		visited[&property.APIType] = true
+
+		if isAPITypeReachable(library, property.APIType, target, visited) {
+			return true
+		}
+		if typ, typeLibrary, err := library.getTypeWithLibrary(property.BaseType); err == nil && !visited[typ] {
+			visited[typ] = true
+			if isAPITypeReachable(typeLibrary, *typ, target, visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resolved return APIType with properties of recursive reference resolved
+func (t APIType) resolved() APIType {
+	if t.refType == nil {
+		return t
+	}
+	result := t
+	mergeObjectType(&result.ObjectType, t.refType.ObjectType)
+	result.refType = nil
+	return result
+}
+
 var _ fillExample = &APIType{}
 
 func (t *APIType) fillExample(conf PostProcessConfig) (err error) {
@@ -176,6 +435,11 @@ func (t *APIType) fillExample(conf PostProcessConfig) (err error) {
 		return
 	}
 
+	if options, generate := generateValueOptionsOf(conf); generate {
+		if err = t.fillGeneratedExample(*conf.Library(), options); err != nil {
+			return
+		}
+	}
 	if t.Example.IsEmpty() {
 		if t.Example, err = generateExample(*conf.Library(), *t, false); err != nil {
 			return
@@ -199,15 +463,35 @@ func (t *APIType) fillExample(conf PostProcessConfig) (err error) {
 	return
 }
 
+// fillGeneratedExample fill example by GenerateValue if no example declared
+func (t *APIType) fillGeneratedExample(library Library, options []GenerateValueOption) (err error) {
+	if !t.Example.IsEmpty() || !t.Examples.IsEmpty() {
+		return
+	}
+	example := newExample()
+	if example.Value, err = generateValueWithOptions(*t, library, options...); err != nil {
+		return
+	}
+	if example.IsEmpty() {
+		return
+	}
+	t.Example = example
+	t.Examples = Examples{
+		exampleNameAutoGenerated: &example,
+	}
+	return
+}
+
 func fillExampleAPIType(example *Example, conf PostProcessConfig, apiType APIType) (err error) {
 	if example == nil || example.IsEmpty() {
 		return
 	}
 
 	if example.includeTag && TypeString == example.Value.Type {
-		fpath := filepath.Join(conf.RootDocument().WorkingDirectory, example.Value.String)
+		// value is the canonical location of included binary file, the size
+		// is counted when the file is included
 		var fdata []byte
-		if fdata, err = ioutil.ReadFile(fpath); err != nil {
+		if fdata, _, err = fileLoaderOf(conf.Parser()).readFile("", example.Value.String); err != nil {
 			return
 		}
 		switch apiType.Type {
@@ -220,6 +504,17 @@ func fillExampleAPIType(example *Example, conf PostProcessConfig, apiType APITyp
 		}
 	}
 
+	if example.includeText && TypeString == example.Value.Type &&
+		(apiType.IsArray || apiType.NativeType != TypeString) {
+		// included text looks like JSON or YAML, which is the example value
+		// unless the example is a string
+		value := Value{}
+		if err = fileLoaderOf(conf.Parser()).unmarshalYAML([]byte(example.Value.String), &value); err != nil {
+			return
+		}
+		example.Value = value
+	}
+
 	if err = fillValueFromAPIType(&example.Value, *conf.Library(), apiType); err != nil {
 		return
 	}
@@ -243,14 +538,20 @@ func fillValueFromAPIType(value *Value, library Library, apiType APIType) (err e
 		return
 	}
 
+	properties := apiType.resolved().Properties.Map()
 	for name, v := range value.Map {
 		if v == nil {
 			v = &Value{}
 			value.Map[name] = v
+		} else if v.IsEmpty() {
+			// generated empty value, e.g. the end of recursive type
+			continue
 		}
-		property := apiType.Properties.Map()[name]
+		property := properties[name]
 		if property == nil {
-			return ErrorPropertyUndefined2.New(nil, name, apiType.Type)
+			undefinedErr := newUndefinedError(ErrorPropertyUndefined2, UndefinedProperty, name, name, apiType.Type)
+			undefinedErr.Type = apiType.Type
+			return undefinedErr
 		}
 		if err = fillValueFromAPIType(v, library, property.APIType); err != nil {
 			return
@@ -266,26 +567,37 @@ func (t *APIType) checkExample(conf PostProcessConfig) (err error) {
 		return
 	}
 
-	options := []CheckValueOption{}
-	confOptions, err := conf.Parser().Get(parserConfig.CheckValueOptions)
-	if err == nil {
-		if opts, ok := confOptions.([]CheckValueOption); ok {
-			options = opts
+	return t.checkExampleValue(checkValueOptionsOf(conf))
+}
+
+// checkExampleValue check values of strict examples, named examples are
+// checked in the order of names before the example which MAY be filled by
+// one of them
+func (t APIType) checkExampleValue(options []CheckValueOption) (err error) {
+	names := []string{}
+	for name := range t.Examples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		example := t.Examples[name]
+		if example == nil || !example.Strict || example.Value.IsZero() {
+			continue
+		}
+		if example.Generated {
+			// e.g. alias of the singular example, which is checked below
+			continue
+		}
+		if err = checkValueAPITypeWithOptions(t, example.Value, options...); err != nil {
+			return appendErrorParent(err, ErrorExampleValue1.New(nil, name))
 		}
 	}
 
-	if !t.Example.Value.IsZero() {
-		if err = CheckValueAPIType(*t, t.Example.Value, options...); err != nil {
+	if t.Example.Strict && !t.Example.Value.IsZero() {
+		if err = checkValueAPITypeWithOptions(t, t.Example.Value, options...); err != nil {
 			return
 		}
 	}
-	for _, example := range t.Examples {
-		if !example.Value.IsZero() {
-			if err = CheckValueAPIType(*t, example.Value, options...); err != nil {
-				return
-			}
-		}
-	}
 
 	return
 }
@@ -376,7 +688,7 @@ func NewValueWithAPIType(apiType APIType, src interface{}) (Value, error) {
 				Type: TypeObject,
 				Map:  map[string]*Value{},
 			}
-			for name, prop := range apiType.Properties.Map() {
+			for name, prop := range apiType.resolved().Properties.Map() {
 				var propval Value
 				if propval, err = NewValueWithAPIType(prop.APIType, srcval.Map[name]); err != nil {
 					return val, err
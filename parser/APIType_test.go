@@ -16,7 +16,7 @@ func getAPITypeFromString(ramlStr string) APIType {
 		panic(err)
 	}
 
-	conf := newPostProcessConfig(nil, nil, nil, nil, nil)
+	conf := newPostProcessConfig(nil, nil, nil, nil, nil, nil)
 	if err := postProcess(&apiType, conf); err != nil {
 		panic(err)
 	}
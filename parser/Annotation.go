@@ -1,9 +1,13 @@
 package parser
 
 import (
+	"encoding/json"
+	"reflect"
+	"sort"
 	"strings"
 
-	"github.com/tsaikd/go-raml-parser/parser/parserConfig"
+	"github.com/tsaikd/KDGoLib/jsonex"
+	"github.com/tsaikd/yaml"
 )
 
 // Annotations map of Annotation
@@ -72,12 +76,28 @@ func (t Annotations) fillAnnotation(library Library) (err error) {
 	return
 }
 
+// checkAnnotationValue check values of annotations not walked by postProcess,
+// e.g. annotations of properties
+func (t Annotations) checkAnnotationValue(conf PostProcessConfig) (err error) {
+	names := []string{}
+	for name := range t {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err = t[name].checkAnnotation(conf); err != nil {
+			return
+		}
+	}
+	return
+}
+
 var _ checkUnusedAnnotation = Annotations{}
 
 func (t Annotations) checkUnusedAnnotation(conf PostProcessConfig) (err error) {
 	annotationUsage := conf.AnnotationUsage()
 	for name := range t {
-		delete(annotationUsage, conf.Library().Prefix()+name)
+		annotationUsage[conf.Library().Prefix()+name] = false
 	}
 	return
 }
@@ -90,34 +110,124 @@ type Annotation struct {
 	Name string `yaml:"-"`
 	// fill by fillAnnotation
 	AnnotationType AnnotationType `yaml:"-"`
+	// source position of the node
+	Position Position `yaml:"-"`
+	// library of the annotation, fill by fillAnnotation to resolve members
+	// of union annotation type
+	library *Library
 }
 
 var _ fillAnnotation = &Annotation{}
 
 func (t *Annotation) fillAnnotation(library Library) (err error) {
 	name := t.Name
-	annotype := library.AnnotationTypes[name]
-	if annotype == nil {
-		return ErrorAnnotationTypeUndefined1.New(nil, name)
+	if t.AnnotationType, err = library.GetAnnotationType(name); err != nil {
+		return
 	}
-	t.AnnotationType = *annotype
+	t.library = &library
 	return
 }
 
 var _ checkAnnotation = Annotation{}
 
 func (t Annotation) checkAnnotation(conf PostProcessConfig) (err error) {
-	options := []CheckValueOption{}
-	confOptions, err := conf.Parser().Get(parserConfig.CheckValueOptions)
-	if err == nil {
-		if opts, ok := confOptions.([]CheckValueOption); ok {
-			options = opts
-		}
+	options := checkValueOptionsOf(conf)
+	if err = checkValueUnionAPIType(*conf.Library(), t.AnnotationType.APIType, t.Value, options...); err != nil {
+		return withPosition(t.valueError(err), t.Position)
 	}
 
-	if err = CheckValueAPIType(t.AnnotationType.APIType, t.Value, options...); err != nil {
+	return nil
+}
+
+// valueError append annotation name to the error chain of checking value
+func (t Annotation) valueError(err error) error {
+	return appendErrorParent(err, ErrorAnnotationValue1.New(nil, t.Name))
+}
+
+// Decode check annotation value against the annotation type, then decode the
+// value into v by json tags
+func (t Annotation) Decode(v interface{}) (err error) {
+	data, err := t.marshalValue()
+	if err != nil {
 		return
 	}
+	if err = json.Unmarshal(data, v); err != nil {
+		return exportError(ErrorAnnotationDecode1.New(err, t.Name))
+	}
+	return nil
+}
 
+// DecodeYAML check annotation value against the annotation type, then decode
+// the value into v by yaml tags
+func (t Annotation) DecodeYAML(v interface{}) (err error) {
+	data, err := t.marshalValue()
+	if err != nil {
+		return
+	}
+	var src interface{}
+	if err = json.Unmarshal(data, &src); err != nil {
+		return exportError(ErrorAnnotationDecode1.New(err, t.Name))
+	}
+	if data, err = yaml.Marshal(src); err != nil {
+		return exportError(ErrorAnnotationDecode1.New(err, t.Name))
+	}
+	if err = yaml.Unmarshal(data, v); err != nil {
+		return exportError(ErrorAnnotationDecode1.New(err, t.Name))
+	}
 	return nil
 }
+
+// marshalValue return JSON of annotation value if it is valid for the
+// annotation type
+func (t Annotation) marshalValue() (data []byte, err error) {
+	if t.library == nil {
+		// library is unknown if the document is loaded from cache
+		err = checkValueAPITypeWithOptions(t.AnnotationType.APIType, t.Value)
+	} else {
+		err = checkValueUnionAPIType(*t.library, t.AnnotationType.APIType, t.Value)
+	}
+	if err != nil {
+		return nil, exportError(t.valueError(err))
+	}
+	if data, err = jsonex.Marshal(t.Value); err != nil {
+		return nil, exportError(ErrorAnnotationDecode1.New(err, t.Name))
+	}
+	return
+}
+
+var reflectTypeAnnotations = reflect.TypeOf(Annotations{})
+
+// FindAnnotation return annotation of node by name, node is any parsed node
+// with Annotations, e.g. *Resource, Method, *APIType or Annotations of
+// a facet in ScalarAnnotations,
+// name MAY be prefixed by library names and enclosed in parentheses
+func FindAnnotation(node interface{}, name string) (annotation *Annotation, ok bool) {
+	name = strings.TrimPrefix(name, "(")
+	name = strings.TrimSuffix(name, ")")
+
+	annotations, ok := node.(Annotations)
+	if !ok {
+		value := reflect.Indirect(reflect.ValueOf(node))
+		if value.Kind() != reflect.Struct {
+			return nil, false
+		}
+		field := value.FieldByName("Annotations")
+		if !field.IsValid() || field.Type() != reflectTypeAnnotations {
+			return nil, false
+		}
+		annotations = field.Interface().(Annotations)
+	}
+
+	annotation = annotations[name]
+	return annotation, annotation != nil
+}
+
+// DecodeAnnotation find annotation of node by name and decode the value into
+// v by json tags, return false if annotation not found
+func DecodeAnnotation(node interface{}, name string, v interface{}) (found bool, err error) {
+	annotation, found := FindAnnotation(node, name)
+	if !found {
+		return false, nil
+	}
+	return true, annotation.Decode(v)
+}
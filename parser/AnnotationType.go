@@ -20,7 +20,7 @@ var _ fixEmptyAnnotation = AnnotationTypes{}
 func (t AnnotationTypes) fixEmptyAnnotation() (err error) {
 	for name, elem := range t {
 		if elem == nil {
-			elem = &AnnotationType{}
+			elem = &AnnotationType{APIType: *NewAPIType()}
 			elem.setType(TypeString)
 			t[name] = elem
 		}
@@ -38,10 +38,24 @@ type AnnotationType struct {
 	// The value MUST be one or more of the options described in the
 	// Target Locations.
 	AllowedTargets TargetLocations `yaml:"allowedTargets" json:"allowedTargets,omitempty"`
+
+	// The field used for check typo error in RAML file
+	TypoCheck typoCheck `yaml:"-" json:"-"`
 }
 
 // UnmarshalYAML implement yaml unmarshaler
 func (t *AnnotationType) UnmarshalYAML(unmarshaler func(interface{}) error) (err error) {
+	var name string
+	if err = unmarshaler(&name); err == nil {
+		// facets of shorthand declaration are defaults
+		t.APIType = *NewAPIType()
+		t.setType(name)
+		return
+	}
+	if !isErrorYAMLIntoString(err) {
+		return
+	}
+
 	if err = unmarshaler(&t.APIType); err != nil {
 		return
 	}
@@ -53,6 +67,9 @@ func (t *AnnotationType) UnmarshalYAML(unmarshaler func(interface{}) error) (err
 		return
 	}
 	t.AllowedTargets = buf.AllowedTargets
+	// keys of AnnotationType are unknown to APIType
+	t.TypoCheck, t.APIType.TypoCheck = t.APIType.TypoCheck, nil
+	t.TypoCheck.remove(annotationTypeTypoKeys)
 
 	return nil
 }
@@ -63,6 +80,17 @@ func (t AnnotationType) IsEmpty() bool {
 		t.AllowedTargets.IsEmpty()
 }
 
+var annotationTypeTypoKeys = apiTypeTypoKeys.withNames("allowedTargets")
+
+var _ checkTypoError = AnnotationType{}
+
+func (t AnnotationType) checkTypoError() (err error) {
+	if !t.TypoCheck.IsEmpty() {
+		return newUnknownFacetError("AnnotationType", t.TypoCheck.Names(), annotationTypeTypoKeys)
+	}
+	return t.APIType.checkTypoError()
+}
+
 var _ checkAnnotation = AnnotationType{}
 
 func (t AnnotationType) checkAnnotation(conf PostProcessConfig) (err error) {
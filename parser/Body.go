@@ -1,5 +1,7 @@
 package parser
 
+import "strings"
+
 // Bodies map of Body
 type Bodies map[string]*Body
 
@@ -36,7 +38,7 @@ type Bodies map[string]*Body
 // UnmarshalYAML unmarshal from YAML
 func (t *Bodies) UnmarshalYAML(unmarshaler func(interface{}) error) (err error) {
 	mimetype := map[string]*Body{}
-	if err = unmarshaler(&mimetype); err == nil {
+	if err = unmarshaler(&mimetype); err == nil && isMediaTypeKeys(mimetype) {
 		*t = mimetype
 		return
 	}
@@ -52,6 +54,17 @@ func (t *Bodies) UnmarshalYAML(unmarshaler func(interface{}) error) (err error)
 	return
 }
 
+// isMediaTypeKeys return true if all keys are media types, otherwise the keys
+// are facets of a body, e.g. properties
+func isMediaTypeKeys(mimetype map[string]*Body) bool {
+	for key := range mimetype {
+		if !strings.Contains(key, "/") {
+			return false
+		}
+	}
+	return true
+}
+
 // IsEmpty return true if it is empty
 func (t Bodies) IsEmpty() bool {
 	for _, elem := range t {
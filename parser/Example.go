@@ -1,5 +1,7 @@
 package parser
 
+import "github.com/tsaikd/yaml"
+
 // Examples The OPTIONAL examples facet can be used to attach multiple examples
 // to a type declaration. Its value is a map of key-value pairs, where each key
 // represents a unique identifier for an example and the value is a single example.
@@ -37,14 +39,47 @@ type SingleExample struct {
 	// that annotation.
 	Annotations Annotations `yaml:",regexp:\\(.*\\)" json:"annotations,omitempty"`
 
+	// Annotations of scalar-valued nodes in value form keyed by the facet
+	// name, e.g. displayName.
+	ScalarAnnotations ScalarAnnotations `yaml:"-" json:"scalarAnnotations,omitempty"`
+
+	// The field used for check typo error of scalar-valued nodes in value form
+	ScalarTypoCheck scalarTypoCheck `yaml:"-" json:"-"`
+
 	// The actual example of a type instance.
 	Value Value `yaml:"value" json:"value,omitempty"`
 
 	// Validates the example against any type declaration (the default), or not.
 	// Set this to false avoid validation.
-	Strict bool `yaml:"strict" json:"strict,omitempty"`
+	Strict bool `yaml:"strict" json:"strict,omitdefault" default:"true"`
+
+	// source position of the node
+	Position Position `yaml:"-" json:"-"`
+}
+
+// BeforeUnmarshalYAML implement yaml Initiator
+func (t *SingleExample) BeforeUnmarshalYAML() (err error) {
+	t.Strict = true
+	return
+}
+
+// UnmarshalYAML unmarshal SingleExample from YAML
+func (t *SingleExample) UnmarshalYAML(unmarshaler func(interface{}) error) (err error) {
+	type plain SingleExample
+	return unmarshalScalarAnnotations(
+		unmarshaler,
+		func() error { return unmarshaler((*plain)(t)) },
+		map[string]*string{
+			"displayName": &t.DisplayName,
+			"description": &t.Description,
+		},
+		&t.ScalarAnnotations,
+		&t.ScalarTypoCheck,
+	)
 }
 
+var singleExampleTypoKeys = newTypoKeys(SingleExample{})
+
 // IsEmpty return true if Example is empty
 func (t SingleExample) IsEmpty() bool {
 	return t.DisplayName == "" &&
@@ -53,6 +88,12 @@ func (t SingleExample) IsEmpty() bool {
 		t.Value.IsEmpty()
 }
 
+var _ checkTypoError = SingleExample{}
+
+func (t SingleExample) checkTypoError() (err error) {
+	return t.ScalarTypoCheck.checkTypoError()
+}
+
 var _ checkAnnotation = SingleExample{}
 
 func (t SingleExample) checkAnnotation(conf PostProcessConfig) (err error) {
@@ -63,19 +104,35 @@ func (t SingleExample) checkAnnotation(conf PostProcessConfig) (err error) {
 type Example struct {
 	SingleExample
 
+	// is the example generated instead of declared in RAML
+	Generated bool `yaml:"-" json:"-"`
+
 	// is include tag set
 	includeTag bool
+	// is included from text file which looks like JSON or YAML
+	includeText bool
+}
+
+// newExample return empty generated Example validated by default
+func newExample() Example {
+	example := Example{Generated: true}
+	example.Strict = true
+	return example
 }
 
 // UnmarshalYAMLTag unmarshal an Example which MIGHT be a simple string or a
-// map[string]interface{}
+// map[string]interface{}, a map with any key of SingleExample is unmarshaled
+// as SingleExample, otherwise it is the example value
 func (t *Example) UnmarshalYAMLTag(unmarshaler func(interface{}) error, tag string) (err error) {
-	if err = unmarshaler(&t.SingleExample); err == nil && !t.SingleExample.IsEmpty() {
-		return
+	if isSingleExampleNode(unmarshaler) {
+		return unmarshaler(&t.SingleExample)
 	}
 
-	if tag == "!include" {
+	switch tag {
+	case "!include":
 		t.includeTag = true
+	case includeTextTag:
+		t.includeText = true
 	}
 
 	if err = unmarshaler(&t.Value); err == nil && !t.Value.IsEmpty() {
@@ -85,7 +142,33 @@ func (t *Example) UnmarshalYAMLTag(unmarshaler func(interface{}) error, tag stri
 	return
 }
 
+// isSingleExampleNode return true if the node is a map with any key of
+// SingleExample, e.g. value or strict
+func isSingleExampleNode(unmarshaler func(interface{}) error) bool {
+	keys := yaml.MapSlice{}
+	if unmarshaler(&keys) != nil {
+		return false
+	}
+	for _, item := range keys {
+		if name, ok := item.Key.(string); ok && singleExampleTypoKeys.match(name) {
+			return true
+		}
+	}
+	return false
+}
+
 func generateExampleValue(library Library, apiType APIType, preferArray bool) (value Value, err error) {
+	return generateExampleValueRecursive(library, apiType, preferArray, map[*APIType]bool{})
+}
+
+// generateExampleValueRecursive generate example value,
+// expanding is the declared types in generating, used to stop recursive types
+func generateExampleValueRecursive(
+	library Library,
+	apiType APIType,
+	preferArray bool,
+	expanding map[*APIType]bool,
+) (value Value, err error) {
 	if apiType.IsArray {
 		result := []interface{}{}
 		for _, example := range apiType.Examples {
@@ -127,11 +210,12 @@ func generateExampleValue(library Library, apiType APIType, preferArray bool) (v
 		}
 	}
 
-	switch apiType.BaseType {
-	case TypeObject:
+	typ, typeLibrary, reusable := getExampleReusableType(library, apiType)
+	switch {
+	case apiType.BaseType == TypeObject, !reusable && apiType.NativeType == TypeObject:
 		valmap := map[string]interface{}{}
-		for _, property := range apiType.Properties.Slice() {
-			if valmap[property.Name], err = generateExampleValue(library, property.APIType, false); err != nil {
+		for _, property := range apiType.resolved().Properties.Slice() {
+			if valmap[property.Name], err = generateExampleValueRecursive(library, property.APIType, false, expanding); err != nil {
 				return
 			}
 		}
@@ -139,14 +223,40 @@ func generateExampleValue(library Library, apiType APIType, preferArray bool) (v
 			return NewValue([]interface{}{valmap})
 		}
 		return NewValue(valmap)
-	default:
-		if typ, exist := library.Types[apiType.BaseType]; exist {
-			return generateExampleValue(library, *typ, apiType.IsArray || preferArray)
+	case reusable:
+		if expanding[typ] {
+			// stop generating recursive type
+			return Value{}, nil
 		}
+		expanding[typ] = true
+		defer delete(expanding, typ)
+		return generateExampleValueRecursive(typeLibrary, *typ, apiType.IsArray || preferArray, expanding)
+	default:
 		return Value{}, nil
 	}
 }
 
+// getExampleReusableType return the declared type of apiType if its examples
+// are valid for apiType, i.e. apiType does not add or override any property,
+// typeLibrary is the library declaring the type
+func getExampleReusableType(library Library, apiType APIType) (typ *APIType, typeLibrary Library, reusable bool) {
+	typ, typeLibrary, err := library.getTypeWithLibrary(apiType.BaseType)
+	if err != nil {
+		return nil, library, false
+	}
+	properties := apiType.resolved().Properties.Slice()
+	typProperties := typ.resolved().Properties.Map()
+	if len(properties) != len(typProperties) {
+		return typ, typeLibrary, false
+	}
+	for _, property := range properties {
+		if typProperties[property.Name] != property {
+			return typ, typeLibrary, false
+		}
+	}
+	return typ, typeLibrary, true
+}
+
 func generateExample(library Library, apiType APIType, preferArray bool) (result Example, err error) {
 	if !apiType.Example.IsEmpty() {
 		if !apiType.IsArray && preferArray {
@@ -160,7 +270,7 @@ func generateExample(library Library, apiType APIType, preferArray bool) (result
 	}
 	if !apiType.Examples.IsEmpty() {
 		if !apiType.IsArray && preferArray {
-			example := apiType.Example
+			example := newExample()
 			if example.Value, err = generateExampleValue(library, apiType, preferArray); err != nil {
 				return Example{}, err
 			}
@@ -173,26 +283,30 @@ func generateExample(library Library, apiType APIType, preferArray bool) (result
 		}
 	}
 
-	if typ, exist := library.Types[apiType.BaseType]; exist {
-		return generateExample(library, *typ, apiType.IsArray || preferArray)
+	if typ, typeLibrary, reusable := getExampleReusableType(library, apiType); reusable {
+		return generateExample(typeLibrary, *typ, apiType.IsArray || preferArray)
 	}
 
-	example := Example{}
+	example := newExample()
 	if example.Value, err = generateExampleValue(library, apiType, apiType.IsArray || preferArray); err != nil {
 		return Example{}, err
 	}
 	return example, nil
 }
 
+// exampleNameAutoGenerated name of generated example in Examples, which MAY
+// be the alias of the singular example
+const exampleNameAutoGenerated = "autoGenerated"
+
 func generateExamples(library Library, apiType APIType, preferArray bool) (result Examples, err error) {
 	if !apiType.Examples.IsEmpty() {
 		if !apiType.IsArray && preferArray {
-			example := apiType.Example
+			example := newExample()
 			if example.Value, err = generateExampleValue(library, apiType, preferArray); err != nil {
 				return Examples{}, err
 			}
 			return Examples{
-				"autoGenerated": &example,
+				exampleNameAutoGenerated: &example,
 			}, nil
 		}
 		return apiType.Examples, nil
@@ -200,29 +314,33 @@ func generateExamples(library Library, apiType APIType, preferArray bool) (resul
 	if !apiType.Example.IsEmpty() {
 		if !apiType.IsArray && preferArray {
 			example := apiType.Example
+			example.Generated = true
 			if example.Value, err = generateExampleValue(library, apiType, preferArray); err != nil {
 				return Examples{}, err
 			}
 			return Examples{
-				"autoGenerated": &example,
+				exampleNameAutoGenerated: &example,
 			}, nil
 		}
+		// alias of the singular example, which is not declared in examples
+		example := apiType.Example
+		example.Generated = true
 		return Examples{
-			"autoGenerated": &apiType.Example,
+			exampleNameAutoGenerated: &example,
 		}, nil
 	}
 
-	if typ, exist := library.Types[apiType.BaseType]; exist {
-		return generateExamples(library, *typ, apiType.IsArray || preferArray)
+	if typ, typeLibrary, reusable := getExampleReusableType(library, apiType); reusable {
+		return generateExamples(typeLibrary, *typ, apiType.IsArray || preferArray)
 	}
 
-	example := Example{}
+	example := newExample()
 	if example.Value, err = generateExampleValue(library, apiType, apiType.IsArray); err != nil {
 		return Examples{}, nil
 	}
 	if !example.IsEmpty() {
 		return Examples{
-			"autoGenerated": &example,
+			exampleNameAutoGenerated: &example,
 		}, nil
 	}
 
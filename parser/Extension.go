@@ -0,0 +1,307 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/tsaikd/yaml"
+)
+
+// yamlNode YAML tree which keeps key order and tags, used to merge overlays
+// and extensions into the master RAML before parsing
+type yamlNode struct {
+	Tag string
+	// yamlMap, []*yamlNode or scalar value
+	Value interface{}
+}
+
+type yamlMapItem struct {
+	Key   interface{}
+	Value *yamlNode
+}
+
+type yamlMap []yamlMapItem
+
+// UnmarshalYAMLTag unmarshal yamlNode with the tag of node
+func (t *yamlNode) UnmarshalYAMLTag(unmarshaler func(interface{}) error, tag string) (err error) {
+	t.Tag = tag
+
+	var value interface{}
+	if err = unmarshaler(&value); err != nil {
+		return
+	}
+
+	switch value.(type) {
+	case map[interface{}]interface{}:
+		keys := yaml.MapSlice{}
+		if err = unmarshaler(&keys); err != nil {
+			return
+		}
+		nodes := map[interface{}]*yamlNode{}
+		if err = unmarshaler(&nodes); err != nil {
+			return
+		}
+		result := yamlMap{}
+		for _, item := range keys {
+			node := nodes[item.Key]
+			if node == nil {
+				node = &yamlNode{}
+			}
+			result = append(result, yamlMapItem{Key: item.Key, Value: node})
+		}
+		t.Value = result
+	case []interface{}:
+		nodes := []*yamlNode{}
+		if err = unmarshaler(&nodes); err != nil {
+			return
+		}
+		t.Value = nodes
+	default:
+		t.Value = value
+	}
+	return
+}
+
+// get return the value node of key if node is a map
+func (t yamlNode) get(key string) *yamlNode {
+	if nodes, ok := t.Value.(yamlMap); ok {
+		for _, item := range nodes {
+			if fmt.Sprint(item.Key) == key {
+				return item.Value
+			}
+		}
+	}
+	return nil
+}
+
+// marshalFlow return node in YAML flow style, which keeps the tags
+func (t yamlNode) marshalFlow() []byte {
+	buffer := &bytes.Buffer{}
+	t.writeFlow(buffer)
+	return buffer.Bytes()
+}
+
+func (t yamlNode) writeFlow(buffer *bytes.Buffer) {
+	if strings.HasPrefix(t.Tag, "!") && !strings.HasPrefix(t.Tag, "!!") {
+		buffer.WriteString(t.Tag + " ")
+	}
+
+	switch value := t.Value.(type) {
+	case yamlMap:
+		buffer.WriteString("{")
+		for i, item := range value {
+			if i > 0 {
+				buffer.WriteString(", ")
+			}
+			writeFlowScalar(buffer, item.Key)
+			buffer.WriteString(": ")
+			item.Value.writeFlow(buffer)
+		}
+		buffer.WriteString("}")
+	case []*yamlNode:
+		buffer.WriteString("[")
+		for i, node := range value {
+			if i > 0 {
+				buffer.WriteString(", ")
+			}
+			node.writeFlow(buffer)
+		}
+		buffer.WriteString("]")
+	default:
+		writeFlowScalar(buffer, value)
+	}
+}
+
+func writeFlowScalar(buffer *bytes.Buffer, value interface{}) {
+	switch value := value.(type) {
+	case nil:
+		buffer.WriteString("null")
+	case bool, int, int64, uint64:
+		fmt.Fprint(buffer, value)
+	case json.Number:
+		buffer.WriteString(value.String())
+	case float64:
+		text := strconv.FormatFloat(value, 'f', -1, 64)
+		if !strings.Contains(text, ".") {
+			text += ".0"
+		}
+		buffer.WriteString(text)
+	default:
+		// JSON string is also a valid YAML double-quoted string
+		data, _ := json.Marshal(fmt.Sprint(value))
+		buffer.Write(data)
+	}
+}
+
+// overlayAllowedNodes nodes which overlay can add or override,
+// annotations are also allowed
+var overlayAllowedNodes = map[string]bool{
+	"title":           true,
+	"displayName":     true,
+	"description":     true,
+	"documentation":   true,
+	"usage":           true,
+	"example":         true,
+	"examples":        true,
+	"annotationTypes": true,
+	"uses":            true,
+}
+
+func isOverlayAllowedNode(key string) bool {
+	isAnnotation := strings.HasPrefix(key, "(") && strings.HasSuffix(key, ")")
+	return isAnnotation || overlayAllowedNodes[key]
+}
+
+// mergeExtensionNode merge extension node into master node by the merging
+// rules of RAML spec, changes not allowed for overlay are appended to
+// violations by JSON pointer of the node
+func mergeExtensionNode(
+	master *yamlNode,
+	extension *yamlNode,
+	pointer string,
+	overlay bool,
+	allowed bool,
+	violations *[]string,
+) {
+	masterMap, isMasterMap := master.Value.(yamlMap)
+	extensionMap, isExtensionMap := extension.Value.(yamlMap)
+	if isMasterMap && isExtensionMap {
+		for _, item := range extensionMap {
+			key := fmt.Sprint(item.Key)
+			if pointer == "" && (key == "extends" || key == "usage") {
+				// describe the overlay or extension itself
+				continue
+			}
+			childPointer := pointer + "/" + escapeJSONPointer(key)
+			childAllowed := allowed || isOverlayAllowedNode(key)
+			if node := master.get(key); node != nil {
+				mergeExtensionNode(node, item.Value, childPointer, overlay, childAllowed, violations)
+				continue
+			}
+			if overlay && !childAllowed {
+				*violations = append(*violations, childPointer)
+				continue
+			}
+			masterMap = append(masterMap, item)
+		}
+		master.Value = masterMap
+		return
+	}
+
+	masterNodes, isMasterNodes := master.Value.([]*yamlNode)
+	extensionNodes, isExtensionNodes := extension.Value.([]*yamlNode)
+	if isMasterNodes && isExtensionNodes {
+		exists := map[string]bool{}
+		for _, node := range masterNodes {
+			exists[string(node.marshalFlow())] = true
+		}
+		for _, node := range extensionNodes {
+			if exists[string(node.marshalFlow())] {
+				continue
+			}
+			if overlay && !allowed {
+				*violations = append(*violations, pointer)
+				return
+			}
+			masterNodes = append(masterNodes, node)
+		}
+		master.Value = masterNodes
+		return
+	}
+
+	if reflect.DeepEqual(master, extension) {
+		return
+	}
+	if overlay && !allowed {
+		*violations = append(*violations, pointer)
+		return
+	}
+	*master = *extension
+}
+
+// escapeJSONPointer escape reference token of JSON pointer, RFC 6901
+func escapeJSONPointer(token string) string {
+	return strings.Replace(strings.Replace(token, "~", "~0", -1), "/", "~1", -1)
+}
+
+// rebaseUsesPaths change file paths of uses in node from relative to the
+// document at location to relative to the master document, included
+// documents are resolved before merging, so only uses need to be changed
+func rebaseUsesPaths(node *yamlNode, location string, masterLocation string) {
+	uses := node.get("uses")
+	if uses == nil {
+		return
+	}
+	nodes, ok := uses.Value.(yamlMap)
+	if !ok {
+		return
+	}
+	for _, item := range nodes {
+		path, ok := item.Value.Value.(string)
+		if !ok || filepath.IsAbs(path) {
+			continue
+		}
+		if rel, err := filepath.Rel(filepath.Dir(masterLocation), filepath.Join(filepath.Dir(location), path)); err == nil {
+			item.Value.Value = rel
+		}
+	}
+}
+
+// loadExtensionTree load RAML data of the document at location as yamlNode,
+// overlay and extension are merged into the master RAML declared by extends
+// recursively, return the merged tree and the location of the master RAML
+func loadExtensionTree(loader *fileLoader, data []byte, location string, visited map[string]bool) (tree *yamlNode, masterLocation string, err error) {
+	kind, err := ParseFragmentHeader(data)
+	if err != nil {
+		return
+	}
+
+	tree = &yamlNode{}
+	if err = loader.unmarshalYAML(data, tree); err != nil {
+		return nil, "", ErrorYAMLParseFailed.New(err)
+	}
+	count := 0
+	if err = resolveIncludeNode(loader, tree, location, "", includeContextValue, 0, 0, nil, &count); err != nil {
+		return nil, "", err
+	}
+
+	switch kind {
+	case FragmentKindRootDocument:
+		return tree, location, nil
+	case FragmentKindOverlay, FragmentKindExtension:
+	default:
+		return nil, "", ErrorUnexpectedFragmentKind2.New(nil, FragmentKindExtension, kind)
+	}
+
+	extends := tree.get("extends")
+	if extends == nil {
+		return nil, "", ErrorExtendsUndefined1.New(nil, kind)
+	}
+	masterData, masterPath, err := loader.includeFile(location, fmt.Sprint(extends.Value))
+	if err != nil {
+		return
+	}
+	if visited[masterPath] {
+		return nil, "", ErrorExtendsCyclic1.New(nil, masterPath)
+	}
+	visited[masterPath] = true
+
+	master, masterLocation, err := loadExtensionTree(loader, masterData, masterPath, visited)
+	if err != nil {
+		return
+	}
+
+	rebaseUsesPaths(tree, location, masterLocation)
+	violations := []string{}
+	mergeExtensionNode(master, tree, "", kind == FragmentKindOverlay, false, &violations)
+	if len(violations) > 0 {
+		return nil, "", ErrorOverlayInvalidNodes1.New(nil, strings.Join(violations, ", "))
+	}
+
+	return master, masterLocation, nil
+}
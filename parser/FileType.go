@@ -12,12 +12,14 @@ type FileType struct {
 	// Specifies the minimum number of bytes for a parameter value.
 	// The value MUST be equal to or greater than 0.
 	// Default: 0
-	MinLength int64 `yaml:"minLength" json:"minLength,omitempty"`
+	// The JSON is output by String which shares the same YAML key.
+	MinLength int64 `yaml:"minLength" json:"-"`
 
 	// Specifies the maximum number of bytes for a parameter value.
 	// The value MUST be equal to or greater than 0.
 	// Default: 2147483647
-	MaxLength int64 `yaml:"maxLength" json:"maxLength,omitdefault" default:"2147483647"`
+	// The JSON is output by String which shares the same YAML key.
+	MaxLength int64 `yaml:"maxLength" json:"-" default:"2147483647"`
 }
 
 // BeforeUnmarshalYAML implement yaml Initiator
@@ -0,0 +1,227 @@
+package parser
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/tsaikd/KDGoLib/enumutil"
+)
+
+// FragmentKind the kind of RAML document declared in the first line,
+// e.g. "#%RAML 1.0 Library", a root document does not declare the kind
+type FragmentKind int8
+
+// List all valid enum
+const (
+	// A RAML API definition, the header is "#%RAML 1.0" without kind
+	FragmentKindRootDocument FragmentKind = 1 + iota
+	// An item in the collection of items that is the value of the root-level documentation node
+	FragmentKindDocumentationItem
+	// A data type declaration where the type node may be used
+	FragmentKindDataType
+	// A declaration containing a map where each key is the name of an example
+	FragmentKindNamedExample
+	// A single resource type declaration
+	FragmentKindResourceType
+	// A single trait declaration
+	FragmentKindTrait
+	// A single annotation type declaration
+	FragmentKindAnnotationTypeDeclaration
+	// A RAML library
+	FragmentKindLibrary
+	// An overlay file
+	FragmentKindOverlay
+	// An extension file
+	FragmentKindExtension
+	// A definition of a security scheme
+	FragmentKindSecurityScheme
+)
+
+var factoryFragmentKind = enumutil.NewEnumFactory().
+	Add(FragmentKindRootDocument, "RootDocument").
+	Add(FragmentKindDocumentationItem, "DocumentationItem").
+	Add(FragmentKindDataType, "DataType").
+	Add(FragmentKindNamedExample, "NamedExample").
+	Add(FragmentKindResourceType, "ResourceType").
+	Add(FragmentKindTrait, "Trait").
+	Add(FragmentKindAnnotationTypeDeclaration, "AnnotationTypeDeclaration").
+	Add(FragmentKindLibrary, "Library").
+	Add(FragmentKindOverlay, "Overlay").
+	Add(FragmentKindExtension, "Extension").
+	Add(FragmentKindSecurityScheme, "SecurityScheme").
+	Build()
+
+func (t FragmentKind) String() string {
+	return factoryFragmentKind.String(t)
+}
+
+// MarshalJSON return jsonfy []byte of enum
+func (t FragmentKind) MarshalJSON() ([]byte, error) {
+	return factoryFragmentKind.MarshalJSON(t)
+}
+
+// UnmarshalJSON decode json data to enum
+func (t *FragmentKind) UnmarshalJSON(b []byte) (err error) {
+	return factoryFragmentKind.UnmarshalJSON(t, b)
+}
+
+// ParseFragmentKind string to enum
+func ParseFragmentKind(s string) FragmentKind {
+	enum, err := factoryFragmentKind.ParseString(s)
+	if err != nil {
+		return 0
+	}
+	return enum.(FragmentKind)
+}
+
+// RAMLVersion the only supported RAML version in document header
+const RAMLVersion = "#%RAML 1.0"
+
+// ParseFragmentHeader parse the first line of RAML document and return
+// the declared fragment kind, e.g. "#%RAML 1.0 DataType"
+func ParseFragmentHeader(data []byte) (kind FragmentKind, err error) {
+	firstLine := string(data)
+	if idx := bytes.IndexByte(data, '\n'); idx >= 0 {
+		firstLine = string(data[:idx])
+	}
+	firstLine = strings.TrimSpace(firstLine)
+
+	if !strings.HasPrefix(firstLine, RAMLVersion) {
+		header := firstLine
+		if len(header) > len(RAMLVersion) {
+			header = header[:len(RAMLVersion)]
+		}
+		return 0, ErrorUnexpectedRAMLVersion2.New(nil, RAMLVersion, header)
+	}
+
+	kindName := firstLine[len(RAMLVersion):]
+	if kindName == "" {
+		return FragmentKindRootDocument, nil
+	}
+	if !strings.HasPrefix(kindName, " ") {
+		return 0, ErrorUnexpectedRAMLVersion2.New(nil, RAMLVersion, firstLine)
+	}
+
+	kindName = strings.TrimSpace(kindName)
+	if kind = ParseFragmentKind(kindName); kind < 1 || kind == FragmentKindRootDocument {
+		return 0, ErrorUnknownFragmentKind1.New(nil, kindName)
+	}
+	return kind, nil
+}
+
+// checkFragmentKind check the header of data is one of the expected kinds,
+// document without RAML header is accepted if strict is false
+func checkFragmentKind(data []byte, strict bool, expected ...FragmentKind) (err error) {
+	kind, err := ParseFragmentHeader(data)
+	if err != nil {
+		if !strict && ErrorUnexpectedRAMLVersion2.Match(err) {
+			return nil
+		}
+		return
+	}
+	for _, expect := range expected {
+		if kind == expect {
+			return nil
+		}
+	}
+	return ErrorUnexpectedFragmentKind2.New(nil, expected[0], kind)
+}
+
+// Fragment RAML fragment document, which declares a single node of the kind
+// in the header, only the field corresponding to Kind is filled
+type Fragment struct {
+	Kind FragmentKind `yaml:"-" json:"kind"`
+
+	// Library fragment, or the libraries used by other fragments
+	Library `yaml:"-"`
+
+	DataType          *APIType        `yaml:"-" json:"dataType,omitempty"`
+	NamedExample      Examples        `yaml:"-" json:"namedExample,omitempty"`
+	ResourceType      *Unimplement    `yaml:"-" json:"resourceType,omitempty"`
+	Trait             *Trait          `yaml:"-" json:"trait,omitempty"`
+	AnnotationType    *AnnotationType `yaml:"-" json:"annotationType,omitempty"`
+	DocumentationItem *Unimplement    `yaml:"-" json:"documentationItem,omitempty"`
+	SecurityScheme    *Unimplement    `yaml:"-" json:"securityScheme,omitempty"`
+	// Overlay, Extension and RootDocument
+	RootDocument *RootDocument `yaml:"-" json:"rootDocument,omitempty"`
+
+	// directory of RAML file
+	WorkingDirectory string `yaml:"-" json:",omitempty"`
+}
+
+// fragmentUses libraries used by fragments other than Library
+type fragmentUses struct {
+	Uses Libraries `yaml:"uses"`
+}
+
+// UnmarshalYAML unmarshal Fragment of Kind from YAML
+func (t *Fragment) UnmarshalYAML(unmarshaler func(interface{}) error) (err error) {
+	switch t.Kind {
+	case FragmentKindLibrary:
+		return unmarshaler(&t.Library)
+	case FragmentKindRootDocument, FragmentKindOverlay, FragmentKindExtension:
+		t.RootDocument = &RootDocument{}
+		return unmarshaler(t.RootDocument)
+	}
+
+	uses := fragmentUses{}
+	if err = unmarshaler(&uses); err != nil {
+		return
+	}
+	t.Uses = uses.Uses
+
+	// uses is not a facet of the fragment node
+	usesKeys := newTypoKeys(uses)
+
+	switch t.Kind {
+	case FragmentKindDataType:
+		t.DataType = &APIType{}
+		if err = unmarshaler(t.DataType); err != nil {
+			return
+		}
+		t.DataType.TypoCheck.remove(usesKeys)
+		return
+	case FragmentKindNamedExample:
+		return unmarshaler(&t.NamedExample)
+	case FragmentKindResourceType:
+		t.ResourceType = &Unimplement{}
+		return unmarshaler(t.ResourceType)
+	case FragmentKindTrait:
+		t.Trait = &Trait{}
+		if err = unmarshaler(t.Trait); err != nil {
+			return
+		}
+		t.Trait.TypoCheck.remove(usesKeys)
+		return
+	case FragmentKindAnnotationTypeDeclaration:
+		t.AnnotationType = &AnnotationType{}
+		if err = unmarshaler(t.AnnotationType); err != nil {
+			return
+		}
+		t.AnnotationType.TypoCheck.remove(usesKeys)
+		return
+	case FragmentKindDocumentationItem:
+		t.DocumentationItem = &Unimplement{}
+		return unmarshaler(t.DocumentationItem)
+	case FragmentKindSecurityScheme:
+		t.SecurityScheme = &Unimplement{}
+		return unmarshaler(t.SecurityScheme)
+	default:
+		return ErrorUnknownFragmentKind1.New(nil, t.Kind)
+	}
+}
+
+// IsEmpty return true if it is empty
+func (t Fragment) IsEmpty() bool {
+	return t.Kind == 0 &&
+		t.Library.IsEmpty() &&
+		t.DataType == nil &&
+		t.NamedExample.IsEmpty() &&
+		t.ResourceType == nil &&
+		t.Trait == nil &&
+		t.AnnotationType == nil &&
+		t.DocumentationItem == nil &&
+		t.SecurityScheme == nil &&
+		t.RootDocument == nil &&
+		t.WorkingDirectory == ""
+}
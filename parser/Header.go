@@ -5,6 +5,22 @@ type Headers struct {
 	Properties
 }
 
+var _ checkTypoError = Headers{}
+
+func (t Headers) checkTypoError() (err error) {
+	return t.checkPropertyTypoError()
+}
+
+var _ checkUnusedType = Headers{}
+
+func (t Headers) checkUnusedType(conf PostProcessConfig) (err error) {
+	// properties are not walked by postProcess
+	for _, property := range t.Slice() {
+		markTypeUsage(*conf.Library(), conf.TypeUsage(), &property.APIType)
+	}
+	return
+}
+
 // Header An API's methods can support or require various HTTP headers.
 // The OPTIONAL headers node is used to explicitly specify those headers.
 // The value of the headers node is a map, specifically a properties
@@ -1,11 +1,10 @@
 package parser
 
 import (
-	"io/ioutil"
-	"path/filepath"
+	"sort"
 	"strings"
 
-	"github.com/tsaikd/yaml"
+	"github.com/tsaikd/go-raml-parser/parser/parserConfig"
 )
 
 // Libraries map of Library
@@ -26,19 +25,63 @@ func (t Libraries) IsEmpty() bool {
 var _ loadExternalUse = Libraries{}
 
 func (t Libraries) loadExternalUse(conf PostProcessConfig) (err error) {
-	for name, library := range t {
-		filePath := filepath.Join(conf.RootDocument().WorkingDirectory, library.Name)
+	// nested uses are relative to the library file declaring them
+	location := conf.RootDocument().documentLocation()
+	namespace := ""
+	stack := []string{location}
+	if parent := conf.Library(); parent != nil {
+		if parent.Location != "" {
+			location = parent.Location
+			stack = append(append([]string{}, parent.stack...), location)
+		}
+		namespace = parent.Prefix()
+	}
 
-		fileData, err := ioutil.ReadFile(filePath)
+	loader := fileLoaderOf(conf.Parser())
+	if err = loader.checkIncludeDepth(strings.Count(namespace, ".") + 1); err != nil {
+		return
+	}
+
+	for name, library := range t {
+		fileData, filePath, err := loader.includeLibrary(location, library.Name)
 		if err != nil {
-			return ErrorLoadExternalLibrary1.New(err, filePath)
+			if filePath == "" {
+				filePath = library.Name
+			}
+			return withPosition(ErrorLoadExternalLibrary1.New(err, filePath), library.Position)
+		}
+		for i, using := range stack {
+			if using == filePath {
+				chain := append(append([]string{}, stack[i:]...), filePath)
+				return withPosition(ErrorIncludeCyclic1.New(nil, strings.Join(chain, " -> ")), library.Position)
+			}
+		}
+
+		// document without RAML header is only accepted if not check version
+		strict := true
+		if check, err := conf.Parser().Get(parserConfig.CheckRAMLVersion); err == nil {
+			strict, _ = check.(bool)
+		}
+		if err = checkFragmentKind(fileData, strict, FragmentKindLibrary); err != nil {
+			return withPosition(ErrorLoadExternalLibrary1.New(err, filePath), library.Position)
 		}
 
-		if err = yaml.Unmarshal(fileData, library); err != nil {
-			return ErrorLoadExternalLibrary1.New(err, filePath)
+		if fileData, err = resolveIncludes(loader, fileData, filePath); err != nil {
+			return withPosition(ErrorLoadExternalLibrary1.New(err, filePath), library.Position)
 		}
 
+		// keep the position of uses node
+		position := library.Position
+		if err = loader.unmarshalYAML(fileData, library); err != nil {
+			return withPosition(ErrorLoadExternalLibrary1.New(err, filePath), position)
+		}
+		fillPositions(loader, library, filePath, "")
+		library.Position = position
+
 		library.Name = name
+		library.Location = filePath
+		library.namespace = namespace
+		library.stack = stack
 	}
 	return
 }
@@ -48,6 +91,20 @@ type Library struct {
 	Name string `json:",omitempty"`
 
 	LibraryRAML
+
+	// canonical location of external library file, also used to load nested uses
+	Location string `json:",omitempty"`
+	// position of the uses node
+	Position Position `yaml:"-" json:"-"`
+	// The field used for check typo error in RAML file
+	TypoCheck typoCheck `yaml:"-" json:"-"`
+	// prefix of the library which uses this library
+	namespace string
+	// canonical locations of documents which use this library, used to
+	// detect cyclic uses
+	stack []string
+	// declared types of the library, built when checking unused types
+	declaredTypes map[*APIType]bool
 }
 
 // UnmarshalYAML unmarshal Library from YAML
@@ -62,42 +119,191 @@ func (t *Library) UnmarshalYAML(unmarshaler func(interface{}) error) (err error)
 	if err = unmarshaler(&t.LibraryRAML); err != nil {
 		return
 	}
+	if t.TypoCheck, err = unmarshalTypoCheck(unmarshaler, libraryTypoKeys); err != nil {
+		return
+	}
 	return
 }
 
+var libraryTypoKeys = newTypoKeys(LibraryRAML{})
+
 // IsEmpty return true if it is empty
 func (t Library) IsEmpty() bool {
 	return t.Name == "" &&
-		t.LibraryRAML.IsEmpty()
+		t.LibraryRAML.IsEmpty() &&
+		t.Location == ""
 }
 
-// GetTrait return trait if found
-func (t Library) GetTrait(name string) (result Trait, err error) {
-	if splits := strings.Split(name, "."); len(splits) == 2 {
-		useName, traitName := splits[0], splits[1]
-		use, ok := t.Uses[useName]
+// LibraryLocations return the canonical location of each used library file,
+// nested used library is named with all library names, e.g. "lib.nested"
+func (t Library) LibraryLocations() map[string]string {
+	result := map[string]string{}
+	for name, library := range t.Uses {
+		if library == nil {
+			continue
+		}
+		result[name] = library.Location
+		for nested, location := range library.LibraryLocations() {
+			result[name+"."+nested] = location
+		}
+	}
+	return result
+}
+
+// resolveUse return the library which declares name and the name without
+// library prefix, name MAY be prefixed by nested library names,
+// e.g. "lib.Person" or "lib.nested.Person"
+func (t Library) resolveUse(name string) (library Library, localName string, err error) {
+	library = t
+	splits := strings.Split(name, ".")
+	for _, useName := range splits[:len(splits)-1] {
+		use, ok := library.Uses[useName]
 		if !ok || use == nil {
-			err = ErrorUseNotFound1.New(nil, useName)
+			err = newUndefinedError(ErrorUseNotFound1, UndefinedUse, useName)
 			return
 		}
-		return use.GetTrait(traitName)
+		library = *use
 	}
+	return library, splits[len(splits)-1], nil
+}
 
-	trait, ok := t.Traits[name]
+// GetType return declared type if found, name MAY be prefixed by library names
+func (t Library) GetType(name string) (result *APIType, err error) {
+	result, _, err = t.getTypeWithLibrary(name)
+	return
+}
+
+// getTypeWithLibrary return declared type and the library which declares it
+func (t Library) getTypeWithLibrary(name string) (result *APIType, library Library, err error) {
+	library, localName, err := t.resolveUse(name)
+	if err != nil {
+		return
+	}
+
+	result, ok := library.Types[localName]
+	if !ok || result == nil {
+		err = newUndefinedError(ErrorTypeUndefined1, UndefinedType, name)
+		return
+	}
+
+	return
+}
+
+// GetTrait return trait if found, name MAY be prefixed by library names
+func (t Library) GetTrait(name string) (result Trait, err error) {
+	library, localName, err := t.resolveUse(name)
+	if err != nil {
+		return
+	}
+
+	trait, ok := library.Traits[localName]
 	if !ok || trait == nil {
-		err = ErrorTraitNotFound1.New(nil, name)
+		err = newUndefinedError(ErrorTraitNotFound1, UndefinedTrait, name)
 		return
 	}
 
 	return *trait, nil
 }
 
-// Prefix return "" if Library is not external used
+// GetAnnotationType return annotation type if found, name MAY be prefixed by library names
+func (t Library) GetAnnotationType(name string) (result AnnotationType, err error) {
+	library, localName, err := t.resolveUse(name)
+	if err != nil {
+		return
+	}
+
+	annotype, ok := library.AnnotationTypes[localName]
+	if !ok || annotype == nil {
+		err = newUndefinedError(ErrorAnnotationTypeUndefined1, UndefinedAnnotationType, name)
+		return
+	}
+
+	return *annotype, nil
+}
+
+// GetResourceType return resource type declaration if found, name MAY be prefixed by library names
+func (t Library) GetResourceType(name string) (result Value, err error) {
+	library, localName, err := t.resolveUse(name)
+	if err != nil {
+		return
+	}
+
+	resourceType, ok := library.ResourceTypes.Map[localName]
+	if !ok || resourceType == nil {
+		err = newUndefinedError(ErrorResourceTypeNotFound1, UndefinedResourceType, name)
+		return
+	}
+
+	return *resourceType, nil
+}
+
+// GetSecurityScheme return security scheme declaration if found, name MAY be prefixed by library names
+func (t Library) GetSecurityScheme(name string) (result Value, err error) {
+	library, localName, err := t.resolveUse(name)
+	if err != nil {
+		return
+	}
+
+	securityScheme, ok := library.SecuritySchemes.Map[localName]
+	if !ok || securityScheme == nil {
+		err = newUndefinedError(ErrorSecuritySchemeNotFound1, UndefinedSecurityScheme, name)
+		return
+	}
+
+	return *securityScheme, nil
+}
+
+// checkResourceTypeReference check resource type referenced by value is
+// declared, value MAY be empty
+func checkResourceTypeReference(library Library, value Value) (err error) {
+	for _, name := range referenceNames(value) {
+		if _, err = library.GetResourceType(name); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// checkSecuritySchemeReference check security schemes referenced by value
+// are declared, value MAY be empty
+func checkSecuritySchemeReference(library Library, value Value) (err error) {
+	for _, name := range referenceNames(value) {
+		if _, err = library.GetSecurityScheme(name); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// referenceNames return names referenced by value of type or securedBy node,
+// a reference is a name or a map from the name to parameters, null
+// references are ignored, e.g. "securedBy: [null, oauth_2_0]"
+func referenceNames(value Value) (names []string) {
+	switch value.Type {
+	case TypeString:
+		names = append(names, value.String)
+	case TypeObject:
+		for name := range value.Map {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	case TypeArray:
+		for _, elem := range value.Array {
+			if elem != nil {
+				names = append(names, referenceNames(*elem)...)
+			}
+		}
+	}
+	return
+}
+
+// Prefix return "" if Library is not external used,
+// nested used library is prefixed by all library names, e.g. "lib.nested."
 func (t Library) Prefix() string {
 	if t.Name == "" {
 		return ""
 	}
-	return t.Name + "."
+	return t.namespace + t.Name + "."
 }
 
 var _ fillBaseType = &Library{}
@@ -108,44 +314,80 @@ func (t *Library) fillBaseType(library Library) (err error) {
 	}
 
 	for name, apiType := range t.Types {
+		if isInlineAPIType(*apiType) {
+			// no more action if declared by JSON
+			continue
+		}
 		switch apiType.NativeType {
-		case TypeNull, TypeBoolean, TypeInteger, TypeNumber, TypeString, TypeObject, TypeFile:
+		case TypeNull, TypeBoolean, TypeInteger, TypeNumber, TypeString, TypeObject, TypeFile,
+			TypeDateOnly, TypeTimeOnly, TypeDateTimeOnly, TypeDateTime:
 		default:
-			var rootType *APIType
-			rootType, err = getAPIRootType(t.Types, apiType.NativeType)
+			var inheritance []*APIType
+			inheritance, err = getAPIInheritance(*t, apiType.NativeType, name)
 			if err != nil {
-				return
+				return withPosition(err, apiType.Position)
 			}
-			if apiType.Type != rootType.Type {
-				newType := *apiType
-				mergeAPIType(&newType, *rootType)
-				t.Types[name] = &newType
+			parents := []APIType{}
+			for _, parent := range inheritance {
+				if err = checkPropertyOverride(*t, *apiType, *parent); err != nil {
+					return withPosition(err, apiType.Position)
+				}
+				parents = append(parents, *parent)
 			}
+			newType := *apiType
+			mergeAPIType(&newType, parents...)
+			t.Types[name] = &newType
 		}
 	}
 
 	return
 }
 
-func getAPIRootType(apiTypes APITypes, name string) (rootType *APIType, err error) {
-	apiType, ok := apiTypes[name]
-	if !ok {
-		return nil, ErrorTypeUndefined1.New(nil, name)
+// getAPIInheritance return the inheritance chain of type name,
+// from the type itself to the root type which is a RAML built-in type,
+// name MAY be prefixed by library names
+func getAPIInheritance(library Library, name string, inheritance ...string) (result []*APIType, err error) {
+	return getAPIInheritanceWithPrefix(library, "", name, inheritance...)
+}
+
+// getAPIInheritanceWithPrefix resolve parent names of types declared in
+// used libraries relative to the declaring library, prefix is the library
+// path of that library relative to the first one
+func getAPIInheritanceWithPrefix(library Library, prefix string, name string, inheritance ...string) (result []*APIType, err error) {
+	for _, parent := range inheritance {
+		if parent == prefix+name {
+			inheritance = append(inheritance, prefix+name)
+			return nil, ErrorTypeCyclicInheritance1.New(nil, strings.Join(inheritance, " -> "))
+		}
+	}
+
+	apiType, typeLibrary, err := library.getTypeWithLibrary(name)
+	if err != nil {
+		return
 	}
 	switch apiType.NativeType {
-	case TypeNull, TypeBoolean, TypeInteger, TypeNumber, TypeString, TypeObject, TypeFile:
-		return apiType, nil
+	case TypeNull, TypeBoolean, TypeInteger, TypeNumber, TypeString, TypeObject, TypeFile,
+		TypeDateOnly, TypeTimeOnly, TypeDateTimeOnly, TypeDateTime:
+		return []*APIType{apiType}, nil
 	default:
-		return getAPIRootType(apiTypes, apiType.NativeType)
+		typePrefix := prefix + name[:strings.LastIndex(name, ".")+1]
+		if result, err = getAPIInheritanceWithPrefix(typeLibrary, typePrefix, apiType.NativeType, append(inheritance, prefix+name)...); err != nil {
+			return
+		}
+		return append([]*APIType{apiType}, result...), nil
 	}
 }
 
 var _ checkUnusedAnnotation = Library{}
 
 func (t Library) checkUnusedAnnotation(conf PostProcessConfig) (err error) {
+	prefix := t.Prefix()
 	annotationUsage := conf.AnnotationUsage()
 	for name := range t.AnnotationTypes {
-		annotationUsage[name] = true
+		// annotation MAY be used before the library declaring it is walked
+		if _, exist := annotationUsage[prefix+name]; !exist {
+			annotationUsage[prefix+name] = true
+		}
 	}
 	return
 }
@@ -161,6 +403,82 @@ func (t Library) checkUnusedTrait(conf PostProcessConfig) (err error) {
 	return
 }
 
+var _ checkUnusedType = &Library{}
+
+func (t *Library) checkUnusedType(conf PostProcessConfig) (err error) {
+	prefix := t.Prefix()
+	typeUsage := conf.TypeUsage()
+	t.declaredTypes = map[*APIType]bool{}
+	for name, apiType := range t.Types {
+		t.declaredTypes[apiType] = true
+		// type MAY be used before the library declaring it is walked
+		if _, exist := typeUsage[prefix+name]; !exist {
+			typeUsage[prefix+name] = true
+		}
+	}
+	// resource types are not parsed, types whose names appear in string
+	// values of resource types are possibly referenced and treated as used
+	markTypeNamesUsage(*t, typeUsage, valueTypeNames(t.ResourceTypes.Value))
+	return
+}
+
+// valueTypeNames return type names in string values of value recursively
+func valueTypeNames(value Value) (names []string) {
+	switch value.Type {
+	case TypeString:
+		return typeExpressionNames(value.String)
+	case TypeArray:
+		for _, elem := range value.Array {
+			if elem != nil {
+				names = append(names, valueTypeNames(*elem)...)
+			}
+		}
+	case TypeObject:
+		for _, elem := range value.Map {
+			if elem != nil {
+				names = append(names, valueTypeNames(*elem)...)
+			}
+		}
+	}
+	return
+}
+
+// isUsed return true if any member of library or its used libraries is used,
+// should be called after usage of all members are checked
+func (t Library) isUsed(conf PostProcessConfig) bool {
+	prefix := t.Prefix()
+	for name := range t.Types {
+		if unused, exist := conf.TypeUsage()[prefix+name]; exist && !unused {
+			return true
+		}
+	}
+	for name := range t.Traits {
+		if _, unused := conf.TraitUsage()[prefix+name]; !unused {
+			return true
+		}
+	}
+	for name := range t.AnnotationTypes {
+		if unused, exist := conf.AnnotationUsage()[prefix+name]; exist && !unused {
+			return true
+		}
+	}
+	for _, use := range t.Uses {
+		if use != nil && use.isUsed(conf) {
+			return true
+		}
+	}
+	return false
+}
+
+var _ checkTypoError = Library{}
+
+func (t Library) checkTypoError() (err error) {
+	if !t.TypoCheck.IsEmpty() {
+		return newUnknownFacetError("Library", t.TypoCheck.Names(), libraryTypoKeys)
+	}
+	return
+}
+
 var _ checkAnnotation = Library{}
 
 func (t Library) checkAnnotation(conf PostProcessConfig) (err error) {
@@ -202,6 +520,13 @@ type LibraryRAML struct {
 	// that annotation.
 	Annotations Annotations `yaml:",regexp:\\(.*\\)" json:"annotations,omitempty"`
 
+	// Annotations of scalar-valued nodes in value form keyed by the facet
+	// name, e.g. usage.
+	ScalarAnnotations ScalarAnnotations `yaml:"-" json:"scalarAnnotations,omitempty"`
+
+	// The field used for check typo error of scalar-valued nodes in value form
+	ScalarTypoCheck scalarTypoCheck `yaml:"-" json:"-"`
+
 	// Declarations of security schemes for use within the API.
 	SecuritySchemes Unimplement `yaml:"securitySchemes" json:"securitySchemes,omitempty"`
 
@@ -209,6 +534,20 @@ type LibraryRAML struct {
 	Uses Libraries `yaml:"uses" json:"uses,omitempty"`
 }
 
+// UnmarshalYAML unmarshal LibraryRAML from YAML
+func (t *LibraryRAML) UnmarshalYAML(unmarshaler func(interface{}) error) (err error) {
+	type plain LibraryRAML
+	return unmarshalScalarAnnotations(
+		unmarshaler,
+		func() error { return unmarshaler((*plain)(t)) },
+		map[string]*string{
+			"usage": &t.Usage,
+		},
+		&t.ScalarAnnotations,
+		&t.ScalarTypoCheck,
+	)
+}
+
 // IsEmpty return true if it is empty
 func (t LibraryRAML) IsEmpty() bool {
 	return t.Usage == "" &&
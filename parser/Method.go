@@ -37,6 +37,13 @@ type Method struct {
 	// that annotation.
 	Annotations Annotations `yaml:",regexp:\\(.*\\)" json:"annotations,omitempty"`
 
+	// Annotations of scalar-valued nodes in value form keyed by the facet
+	// name, e.g. description.
+	ScalarAnnotations ScalarAnnotations `yaml:"-" json:"scalarAnnotations,omitempty"`
+
+	// The field used for check typo error of scalar-valued nodes in value form
+	ScalarTypoCheck scalarTypoCheck `yaml:"-" json:"-"`
+
 	// Detailed information about any query parameters needed by this method.
 	// Mutually exclusive with queryString.
 	QueryParameters QueryParameters `yaml:"queryParameters" json:"queryParameters,omitempty"`
@@ -66,6 +73,24 @@ type Method struct {
 
 	// The field used for check typo error in RAML file
 	TypoCheck typoCheck `yaml:",regexp:.*" json:"-"`
+
+	// source position of the node
+	Position Position `yaml:"-" json:"-"`
+}
+
+// UnmarshalYAML unmarshal Method from YAML
+func (t *Method) UnmarshalYAML(unmarshaler func(interface{}) error) (err error) {
+	type plain Method
+	return unmarshalScalarAnnotations(
+		unmarshaler,
+		func() error { return unmarshaler((*plain)(t)) },
+		map[string]*string{
+			"displayName": &t.DisplayName,
+			"description": &t.Description,
+		},
+		&t.ScalarAnnotations,
+		&t.ScalarTypoCheck,
+	)
 }
 
 // IsEmpty return true if it is empty
@@ -85,13 +110,21 @@ func (t Method) IsEmpty() bool {
 
 var _ checkTypoError = Method{}
 
+var methodTypoKeys = newTypoKeys(Method{})
+
 func (t Method) checkTypoError() (err error) {
 	if !t.TypoCheck.IsEmpty() {
-		return ErrorTypo2.New(nil, "Method", t.TypoCheck.Names())
+		return newUnknownFacetError("Method", t.TypoCheck.Names(), methodTypoKeys)
 	}
 	return
 }
 
+var _ checkReference = Method{}
+
+func (t Method) checkReference(conf PostProcessConfig) (err error) {
+	return checkSecuritySchemeReference(*conf.Library(), t.SecuredBy.Value)
+}
+
 var _ checkAnnotation = Method{}
 
 func (t Method) checkAnnotation(conf PostProcessConfig) (err error) {
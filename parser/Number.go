@@ -0,0 +1,30 @@
+package parser
+
+// Number facets of number and integer types
+type Number struct {
+	// The minimum value of the parameter. Applicable only to parameters of
+	// type number or integer.
+	Minimum *float64 `yaml:"minimum" json:"minimum,omitempty"`
+
+	// The maximum value of the parameter. Applicable only to parameters of
+	// type number or integer.
+	Maximum *float64 `yaml:"maximum" json:"maximum,omitempty"`
+
+	// The format of the value. The value MUST be one of the following:
+	// int32, int64, int, long, float, double, int16, int8.
+	// The format of datetime type is also declared by this facet, the value
+	// MUST be rfc3339 or rfc2616.
+	Format string `yaml:"format" json:"format,omitempty"`
+
+	// A numeric instance is valid against "multipleOf" if the result of
+	// dividing the instance by this keyword's value is an integer.
+	MultipleOf *float64 `yaml:"multipleOf" json:"multipleOf,omitempty"`
+}
+
+// IsEmpty return true if it is empty
+func (t *Number) IsEmpty() bool {
+	return t.Minimum == nil &&
+		t.Maximum == nil &&
+		t.Format == "" &&
+		t.MultipleOf == nil
+}
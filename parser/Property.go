@@ -112,17 +112,128 @@ func (t *Properties) fixRequiredBySyntax() (err error) {
 	return
 }
 
+// checkPropertyTypoError check typo error of each property, properties are
+// not walked by postProcess
+func (t Properties) checkPropertyTypoError() (err error) {
+	for _, property := range t.Slice() {
+		if err = property.checkTypoError(); err != nil {
+			return withPosition(err, property.Position)
+		}
+		if err = property.ScalarTypoCheck.checkTypoError(); err != nil {
+			return withPosition(err, property.Position)
+		}
+	}
+	return
+}
+
 var _ checkUnusedAnnotation = Properties{}
 
 func (t Properties) checkUnusedAnnotation(conf PostProcessConfig) (err error) {
 	for _, property := range t.Slice() {
-		if err = property.Annotations.checkUnusedAnnotation(conf); err != nil {
-			return
+		for _, annotations := range property.allAnnotations() {
+			if err = annotations.checkUnusedAnnotation(conf); err != nil {
+				return withPosition(err, property.Position)
+			}
+		}
+	}
+	return
+}
+
+var _ checkAnnotation = Properties{}
+
+func (t Properties) checkAnnotation(conf PostProcessConfig) (err error) {
+	for _, property := range t.Slice() {
+		for _, annotations := range property.allAnnotations() {
+			if err = annotations.checkAnnotationValue(conf); err != nil {
+				return withPosition(err, property.Position)
+			}
+		}
+		if err = property.Properties.checkAnnotation(conf); err != nil {
+			return withPosition(err, property.Position)
+		}
+	}
+	return
+}
+
+var _ checkExample = Properties{}
+
+func (t Properties) checkExample(conf PostProcessConfig) (err error) {
+	options := checkValueOptionsOf(conf)
+	for _, property := range t.Slice() {
+		if err = property.APIType.checkExampleValue(options); err != nil {
+			return withPosition(err, property.Position)
+		}
+		if err = property.Properties.checkExample(conf); err != nil {
+			return withPosition(err, property.Position)
 		}
 	}
 	return
 }
 
+// checkPropertyOverride check properties of apiType overriding properties
+// of parent are compatible with the parent property types
+func checkPropertyOverride(library Library, apiType APIType, parent APIType) (err error) {
+	parentProperties := parent.Properties.Map()
+	for _, property := range apiType.Properties.Slice() {
+		parentProperty := parentProperties[property.Name]
+		if parentProperty == nil {
+			continue
+		}
+		if !isAPITypeCompatible(library, property.APIType, parentProperty.APIType) {
+			return withPosition(
+				newTypeMismatchError(
+					ErrorPropertyOverrideIncompatible3,
+					nil,
+					[]string{property.Name},
+					parentProperty.Type,
+					property.Type,
+					property.Name, property.Type, parentProperty.Type,
+				),
+				property.Position,
+			)
+		}
+	}
+	return nil
+}
+
+// isAPITypeCompatible return true if apiType is the same type or a subtype of parent
+func isAPITypeCompatible(library Library, apiType APIType, parent APIType) bool {
+	if parent.Type == "" || apiType.Type == "" || parent.Type == apiType.Type {
+		return true
+	}
+	if isInlineAPIType(parent) || isInlineAPIType(apiType) {
+		// not support compare inline APIType
+		return true
+	}
+	if apiType.IsArray != parent.IsArray {
+		return false
+	}
+
+	inheritance, err := getAPIInheritance(library, apiType.BaseType)
+
+	if parentType, err := library.GetType(parent.BaseType); err == nil {
+		// parent is a declared type, apiType should inherit from it
+		for _, typ := range inheritance {
+			if typ == parentType {
+				return true
+			}
+		}
+		return false
+	}
+
+	nativeType := apiType.BaseType
+	if err == nil {
+		nativeType = inheritance[len(inheritance)-1].NativeType
+	}
+	switch {
+	case nativeType == parent.BaseType:
+		return true
+	case nativeType == TypeInteger && parent.BaseType == TypeNumber:
+		return true
+	}
+	return false
+}
+
 type propertiesSliceData []*Property
 
 // Property of a object type
@@ -145,8 +256,11 @@ func (t *Property) BeforeUnmarshalYAML() (err error) {
 // UnmarshalYAML implement yaml unmarshaler
 // a Property which MIGHT be a simple string or a map[string]interface{}
 func (t *Property) UnmarshalYAML(unmarshaler func(interface{}) error) (err error) {
-	if err = unmarshaler(&t.Type); err == nil {
-		t.setType(t.Type)
+	var name string
+	if err = unmarshaler(&name); err == nil {
+		// facets of shorthand declaration are defaults
+		t.APIType = *NewAPIType()
+		t.setType(name)
 		return
 	}
 	if !isErrorYAMLIntoString(err) {
@@ -5,6 +5,22 @@ type QueryParameters struct {
 	Properties
 }
 
+var _ checkTypoError = QueryParameters{}
+
+func (t QueryParameters) checkTypoError() (err error) {
+	return t.checkPropertyTypoError()
+}
+
+var _ checkUnusedType = QueryParameters{}
+
+func (t QueryParameters) checkUnusedType(conf PostProcessConfig) (err error) {
+	// properties are not walked by postProcess
+	for _, property := range t.Slice() {
+		markTypeUsage(*conf.Library(), conf.TypeUsage(), &property.APIType)
+	}
+	return
+}
+
 // QueryParameter The queryParameters node specifies the set of query
 // parameters from which the query string is composed. When applying the
 // restrictions defined by the API, processors MUST regard the query string
@@ -64,6 +64,13 @@ type Resource struct {
 	// annotation.
 	Annotations Annotations `yaml:",regexp:\\(.*\\)" json:"annotations,omitempty"`
 
+	// Annotations of scalar-valued nodes in value form keyed by the facet
+	// name, e.g. displayName.
+	ScalarAnnotations ScalarAnnotations `yaml:"-" json:"scalarAnnotations,omitempty"`
+
+	// The field used for check typo error of scalar-valued nodes in value form
+	ScalarTypoCheck scalarTypoCheck `yaml:"-" json:"-"`
+
 	// The object describing the method.
 	Methods Methods `yaml:",regexp:(get|patch|put|post|delete|options|head)" json:"methods,omitempty"`
 
@@ -85,6 +92,27 @@ type Resource struct {
 	// A nested resource, which is identified as any node whose name begins
 	// with a slash ("/"), and is therefore treated as a relative URI.
 	Resources Resources `yaml:",regexp:/.*" json:"resources,omitempty"`
+
+	// The field used for check typo error in RAML file
+	TypoCheck typoCheck `yaml:",regexp:.*" json:"-"`
+
+	// source position of the node
+	Position Position `yaml:"-" json:"-"`
+}
+
+// UnmarshalYAML unmarshal Resource from YAML
+func (t *Resource) UnmarshalYAML(unmarshaler func(interface{}) error) (err error) {
+	type plain Resource
+	return unmarshalScalarAnnotations(
+		unmarshaler,
+		func() error { return unmarshaler((*plain)(t)) },
+		map[string]*string{
+			"displayName": &t.DisplayName,
+			"description": &t.Description,
+		},
+		&t.ScalarAnnotations,
+		&t.ScalarTypoCheck,
+	)
 }
 
 // IsEmpty return true if it is empty
@@ -100,6 +128,26 @@ func (t Resource) IsEmpty() bool {
 		t.Resources.IsEmpty()
 }
 
+var _ checkTypoError = Resource{}
+
+var resourceTypoKeys = newTypoKeys(Resource{})
+
+func (t Resource) checkTypoError() (err error) {
+	if !t.TypoCheck.IsEmpty() {
+		return newUnknownFacetError("Resource", t.TypoCheck.Names(), resourceTypoKeys)
+	}
+	return
+}
+
+var _ checkReference = Resource{}
+
+func (t Resource) checkReference(conf PostProcessConfig) (err error) {
+	if err = checkResourceTypeReference(*conf.Library(), t.Type.Value); err != nil {
+		return
+	}
+	return checkSecuritySchemeReference(*conf.Library(), t.SecuredBy.Value)
+}
+
 var _ checkAnnotation = Resource{}
 
 func (t Resource) checkAnnotation(conf PostProcessConfig) (err error) {
@@ -43,11 +43,38 @@ type Response struct {
 	// that annotation.
 	Annotations Annotations `yaml:",regexp:\\(.*\\)" json:"annotations,omitempty"`
 
+	// Annotations of scalar-valued nodes in value form keyed by the facet
+	// name, e.g. description.
+	ScalarAnnotations ScalarAnnotations `yaml:"-" json:"scalarAnnotations,omitempty"`
+
+	// The field used for check typo error of scalar-valued nodes in value form
+	ScalarTypoCheck scalarTypoCheck `yaml:"-" json:"-"`
+
 	// Detailed information about any response headers returned by this method
 	Headers Headers `yaml:"headers" json:"headers,omitempty"`
 
 	// The body of the response
 	Bodies Bodies `yaml:"body" json:"body,omitempty"`
+
+	// The field used for check typo error in RAML file
+	TypoCheck typoCheck `yaml:",regexp:.*" json:"-"`
+
+	// source position of the node
+	Position Position `yaml:"-" json:"-"`
+}
+
+// UnmarshalYAML unmarshal Response from YAML
+func (t *Response) UnmarshalYAML(unmarshaler func(interface{}) error) (err error) {
+	type plain Response
+	return unmarshalScalarAnnotations(
+		unmarshaler,
+		func() error { return unmarshaler((*plain)(t)) },
+		map[string]*string{
+			"description": &t.Description,
+		},
+		&t.ScalarAnnotations,
+		&t.ScalarTypoCheck,
+	)
 }
 
 // IsEmpty return true if it is empty
@@ -58,6 +85,17 @@ func (t Response) IsEmpty() bool {
 		t.Bodies.IsEmpty()
 }
 
+var _ checkTypoError = Response{}
+
+var responseTypoKeys = newTypoKeys(Response{})
+
+func (t Response) checkTypoError() (err error) {
+	if !t.TypoCheck.IsEmpty() {
+		return newUnknownFacetError("Response", t.TypoCheck.Names(), responseTypoKeys)
+	}
+	return
+}
+
 var _ checkAnnotation = Response{}
 
 func (t Response) checkAnnotation(conf PostProcessConfig) (err error) {
@@ -1,6 +1,11 @@
 package parser
 
-import "github.com/tsaikd/go-raml-parser/parser/parserConfig"
+import (
+	"sort"
+	"strings"
+
+	"github.com/tsaikd/go-raml-parser/parser/parserConfig"
+)
 
 // RootDocument The root section of the RAML document describes the basic
 // information about an API, such as its title and version. The root section
@@ -12,6 +17,12 @@ type RootDocument struct {
 
 	// directory of RAML file
 	WorkingDirectory string `json:",omitempty"`
+
+	// The field used for check typo error in RAML file
+	TypoCheck typoCheck `yaml:"-" json:"-"`
+
+	// canonical location of RAML file, used to resolve references
+	location string
 }
 
 // UnmarshalYAML unmarshal RootDocument from YAML
@@ -22,9 +33,14 @@ func (t *RootDocument) UnmarshalYAML(unmarshaler func(interface{}) error) (err e
 	if err = unmarshaler(&t.RootDocumentExtra); err != nil {
 		return
 	}
+	// keys of RootDocumentExtra are unknown to Library
+	t.TypoCheck, t.Library.TypoCheck = t.Library.TypoCheck, nil
+	t.TypoCheck.remove(rootDocumentTypoKeys)
 	return
 }
 
+var rootDocumentTypoKeys = newTypoKeys(RootDocumentExtra{})
+
 // IsEmpty return true if it is empty
 func (t RootDocument) IsEmpty() bool {
 	return t.Library.IsEmpty() &&
@@ -32,6 +48,23 @@ func (t RootDocument) IsEmpty() bool {
 		t.WorkingDirectory == ""
 }
 
+// documentLocation return the location used to resolve references in root document
+func (t RootDocument) documentLocation() string {
+	if t.location != "" {
+		return t.location
+	}
+	return directoryLocation(t.WorkingDirectory)
+}
+
+var _ checkTypoError = RootDocument{}
+
+func (t RootDocument) checkTypoError() (err error) {
+	if !t.TypoCheck.IsEmpty() {
+		return newUnknownFacetError("RootDocument", t.TypoCheck.Names(), newTypoKeys(LibraryRAML{}, RootDocumentExtra{}))
+	}
+	return
+}
+
 var _ afterCheckUnusedAnnotation = RootDocument{}
 
 func (t RootDocument) afterCheckUnusedAnnotation(conf PostProcessConfig) (err error) {
@@ -42,8 +75,21 @@ func (t RootDocument) afterCheckUnusedAnnotation(conf PostProcessConfig) (err er
 	if ignore.(bool) {
 		return
 	}
-	for name := range conf.AnnotationUsage() {
-		return ErrorUnusedAnnotation1.New(nil, name)
+	names := []string{}
+	for name, unused := range conf.AnnotationUsage() {
+		if unused {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		position := Position{}
+		if annotationType, err := t.GetAnnotationType(name); err == nil {
+			position = annotationType.Position
+		}
+		if err = reportWarning(conf, withPosition(ErrorUnusedAnnotation1.New(nil, name), position)); err != nil {
+			return
+		}
 	}
 	return
 }
@@ -58,12 +104,116 @@ func (t RootDocument) afterCheckUnusedTrait(conf PostProcessConfig) (err error)
 	if ignore.(bool) {
 		return
 	}
+	names := []string{}
 	for name := range conf.TraitUsage() {
-		return ErrorUnusedTrait1.New(nil, name)
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		position := Position{}
+		if trait, err := t.GetTrait(name); err == nil {
+			position = trait.Position
+		}
+		if err = reportWarning(conf, withPosition(ErrorUnusedTrait1.New(nil, name), position)); err != nil {
+			return
+		}
+	}
+	return
+}
+
+var _ afterCheckUnusedType = RootDocument{}
+
+func (t RootDocument) afterCheckUnusedType(conf PostProcessConfig) (err error) {
+	// types referenced by used types are also used
+	typeUsage := conf.TypeUsage()
+	queue := []string{}
+	for name, unused := range typeUsage {
+		if !unused {
+			queue = append(queue, name)
+		}
+	}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		apiType, library, err := t.getTypeWithLibrary(name)
+		if err != nil {
+			continue
+		}
+		queue = append(queue, markTypeUsage(library, typeUsage, apiType)...)
+	}
+
+	ignore, err := conf.Parser().Get(parserConfig.IgnoreUnusedType)
+	if err != nil {
+		return
+	}
+	if ignore.(bool) {
+		return
+	}
+	names := []string{}
+	for name, unused := range typeUsage {
+		if unused {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		position := Position{}
+		if apiType, err := t.GetType(name); err == nil {
+			position = apiType.Position
+		}
+		if err = reportWarning(conf, withPosition(ErrorUnusedType1.New(nil, name), position)); err != nil {
+			return
+		}
 	}
 	return
 }
 
+var _ afterCheckUnusedLibrary = RootDocument{}
+
+func (t RootDocument) afterCheckUnusedLibrary(conf PostProcessConfig) (err error) {
+	ignore, err := conf.Parser().Get(parserConfig.IgnoreUnusedLibrary)
+	if err != nil {
+		return
+	}
+	if ignore.(bool) {
+		return
+	}
+	return checkUnusedLibraries(conf, t.Uses)
+}
+
+// checkUnusedLibraries report unused libraries, libraries used by unused
+// library are not reported
+func checkUnusedLibraries(conf PostProcessConfig, libraries Libraries) (err error) {
+	names := []string{}
+	for name := range libraries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		library := libraries[name]
+		if library == nil {
+			continue
+		}
+		if library.isUsed(conf) {
+			if err = checkUnusedLibraries(conf, library.Uses); err != nil {
+				return
+			}
+			continue
+		}
+		fullName := strings.TrimSuffix(library.Prefix(), ".")
+		if err = reportWarning(conf, withPosition(ErrorUnusedLibrary1.New(nil, fullName), library.Position)); err != nil {
+			return
+		}
+	}
+	return
+}
+
+var _ checkReference = RootDocument{}
+
+func (t RootDocument) checkReference(conf PostProcessConfig) (err error) {
+	return checkSecuritySchemeReference(*conf.Library(), t.SecuredBy.Value)
+}
+
 var _ checkAnnotation = RootDocument{}
 
 func (t RootDocument) checkAnnotation(conf PostProcessConfig) (err error) {
@@ -108,6 +258,31 @@ type RootDocumentExtra struct {
 	// either at the root of the API definition or a child of a resource node.
 	// For example, /users and /{groupId}.
 	Resources Resources `yaml:",regexp:/.*" json:"resources,omitempty"`
+
+	// Annotations of scalar-valued nodes in value form keyed by the facet
+	// name, e.g. title and baseUri.
+	ScalarAnnotations ScalarAnnotations `yaml:"-" json:"scalarAnnotations,omitempty"`
+
+	// The field used for check typo error of scalar-valued nodes in value form
+	ScalarTypoCheck scalarTypoCheck `yaml:"-" json:"-"`
+}
+
+// UnmarshalYAML unmarshal RootDocumentExtra from YAML
+func (t *RootDocumentExtra) UnmarshalYAML(unmarshaler func(interface{}) error) (err error) {
+	type plain RootDocumentExtra
+	return unmarshalScalarAnnotations(
+		unmarshaler,
+		func() error { return unmarshaler((*plain)(t)) },
+		map[string]*string{
+			"title":       &t.Title,
+			"description": &t.Description,
+			"version":     &t.Version,
+			"baseUri":     &t.BaseURI,
+			"mediaType":   &t.MediaType,
+		},
+		&t.ScalarAnnotations,
+		&t.ScalarTypoCheck,
+	)
 }
 
 // IsEmpty return true if it is empty
@@ -0,0 +1,167 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/tsaikd/yaml"
+)
+
+// ScalarAnnotations annotations of scalar-valued nodes keyed by the facet
+// name, e.g. title, description and displayName, a scalar-valued node MAY be
+// annotated by the value form:
+//
+//	displayName:
+//	  value: Users
+//	  (deprecated): true
+type ScalarAnnotations map[string]Annotations
+
+// IsEmpty return true if it is empty
+func (t ScalarAnnotations) IsEmpty() bool {
+	for _, elem := range t {
+		if !elem.IsEmpty() {
+			return false
+		}
+	}
+	return true
+}
+
+// all return annotations of all facets in order of facet name
+func (t ScalarAnnotations) all() []Annotations {
+	names := []string{}
+	for name := range t {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	result := []Annotations{}
+	for _, name := range names {
+		result = append(result, t[name])
+	}
+	return result
+}
+
+// scalarTypoCheck unknown keys of scalar-valued nodes in value form keyed by
+// the facet name
+type scalarTypoCheck map[string]typoCheck
+
+var _ checkTypoError = scalarTypoCheck{}
+
+func (t scalarTypoCheck) checkTypoError() (err error) {
+	facets := []string{}
+	for facet := range t {
+		facets = append(facets, facet)
+	}
+	sort.Strings(facets)
+	for _, facet := range facets {
+		if check := t[facet]; !check.IsEmpty() {
+			return newUnknownFacetError(facet, check.Names(), annotatedScalarTypoKeys)
+		}
+	}
+	return
+}
+
+// annotatedScalar value form of scalar-valued node
+type annotatedScalar struct {
+	// The scalar value of the node
+	Value string `yaml:"value"`
+
+	// Annotations to be applied to the scalar-valued node.
+	Annotations Annotations `yaml:",regexp:\\(.*\\)"`
+
+	// The field used for check typo error in RAML file
+	TypoCheck typoCheck `yaml:",regexp:.*"`
+
+	// true if the node is in value form
+	annotated bool
+	// error of unmarshal the node in value form
+	err error
+}
+
+var annotatedScalarTypoKeys = newTypoKeys(annotatedScalar{})
+
+// UnmarshalYAML implement yaml unmarshaler
+// a node in value form is a map[string]interface{}, others are left to the
+// owner of the node
+func (t *annotatedScalar) UnmarshalYAML(unmarshaler func(interface{}) error) (err error) {
+	value := ""
+	if err = unmarshaler(&value); err == nil {
+		return
+	}
+	mapping := struct{}{}
+	if err = unmarshaler(&mapping); err != nil {
+		return nil
+	}
+
+	type plain annotatedScalar
+	t.annotated = true
+	t.err = unmarshaler((*plain)(t))
+	return nil
+}
+
+// unmarshalScalarAnnotations unmarshal the node by unmarshal, and then fill
+// facets which are in value form, annotations and unknown keys of them are
+// added into annotations and typoCheck
+func unmarshalScalarAnnotations(
+	unmarshaler func(interface{}) error,
+	unmarshal func() error,
+	facets map[string]*string,
+	annotations *ScalarAnnotations,
+	typoCheck *scalarTypoCheck,
+) (err error) {
+	scalars := map[interface{}]*annotatedScalar{}
+	if unmarshaler(&scalars) != nil {
+		// not a map, error will be reported by unmarshal
+		scalars = nil
+	}
+
+	names := []string{}
+	for name := range facets {
+		if scalar := scalars[name]; scalar != nil && scalar.annotated {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	if err = unmarshal(); err != nil {
+		if !isErrorYAMLScalarsIntoString(err, len(names)) {
+			return
+		}
+		err = nil
+	}
+
+	for _, name := range names {
+		scalar := scalars[name]
+		if scalar.err != nil {
+			return scalar.err
+		}
+		*facets[name] = scalar.Value
+		if !scalar.Annotations.IsEmpty() {
+			if *annotations == nil {
+				*annotations = ScalarAnnotations{}
+			}
+			(*annotations)[name] = scalar.Annotations
+		}
+		if !scalar.TypoCheck.IsEmpty() {
+			if *typoCheck == nil {
+				*typoCheck = scalarTypoCheck{}
+			}
+			(*typoCheck)[name] = scalar.TypoCheck
+		}
+	}
+	return
+}
+
+// isErrorYAMLScalarsIntoString return true if err is caused only by count
+// scalar-valued nodes in value form unmarshaled into string fields
+func isErrorYAMLScalarsIntoString(err error, count int) bool {
+	typeErr, ok := err.(*yaml.TypeError)
+	if !ok || count < 1 || len(typeErr.Errors) != count {
+		return false
+	}
+	for _, msg := range typeErr.Errors {
+		if !strings.Contains(msg, "into string") {
+			return false
+		}
+	}
+	return true
+}
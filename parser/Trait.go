@@ -35,6 +35,10 @@ type Trait struct {
 	String string `json:",omitempty"`
 
 	TraitRAML
+
+	// The field used for check typo error in RAML file, unknown keys of
+	// embedded Method are moved here to report them on trait
+	TypoCheck typoCheck `yaml:"-" json:"-"`
 }
 
 // UnmarshalYAML implement yaml unmarshaler
@@ -47,21 +51,32 @@ func (t *Trait) UnmarshalYAML(unmarshaler func(interface{}) error) (err error) {
 		return
 	}
 
-	if err = unmarshaler(&t.Method); err != nil {
-		return
-	}
 	if err = unmarshaler(&t.TraitRAML); err != nil {
 		return
 	}
+	t.TypoCheck, t.Method.TypoCheck = t.Method.TypoCheck, nil
+	t.TypoCheck.remove(traitTypoKeys)
 	return
 }
 
+// traitTypoKeys valid keys of trait, e.g. usage
+var traitTypoKeys = newTypoKeys(TraitRAML{})
+
 // IsEmpty return true if it is empty
 func (t Trait) IsEmpty() bool {
 	return t.String == "" &&
 		t.TraitRAML.IsEmpty()
 }
 
+var _ checkTypoError = Trait{}
+
+func (t Trait) checkTypoError() (err error) {
+	if !t.TypoCheck.IsEmpty() {
+		return newUnknownFacetError("Trait", t.TypoCheck.Names(), traitTypoKeys)
+	}
+	return
+}
+
 var _ fillTrait = &Trait{}
 
 func (t *Trait) fillTrait(library Library) (err error) {
@@ -85,6 +100,16 @@ func (t *Trait) fillTrait(library Library) (err error) {
 	return
 }
 
+var _ checkReference = Trait{}
+
+func (t Trait) checkReference(conf PostProcessConfig) (err error) {
+	if t.String != "" {
+		// references are checked where the trait is declared
+		return
+	}
+	return t.Method.checkReference(conf)
+}
+
 var _ checkAnnotation = Trait{}
 
 func (t Trait) checkAnnotation(conf PostProcessConfig) (err error) {
@@ -129,6 +154,37 @@ type TraitRAML struct {
 	MethodName string `yaml:"methodName" json:"methodName,omitempty"`
 }
 
+// UnmarshalYAML unmarshal TraitRAML from YAML, UnmarshalYAML of the embedded
+// Method is promoted to TraitRAML, so fields of TraitRAML are unmarshaled
+// after the embedded Method
+func (t *TraitRAML) UnmarshalYAML(unmarshaler func(interface{}) error) (err error) {
+	if err = unmarshaler(&t.Method); err != nil {
+		return
+	}
+	fields := struct {
+		Usage            string `yaml:"usage"`
+		ResourcePath     string `yaml:"resourcePath"`
+		ResourcePathName string `yaml:"resourcePathName"`
+		MethodName       string `yaml:"methodName"`
+	}{}
+	if err = unmarshalScalarAnnotations(
+		unmarshaler,
+		func() error { return unmarshaler(&fields) },
+		map[string]*string{
+			"usage": &fields.Usage,
+		},
+		&t.ScalarAnnotations,
+		&t.ScalarTypoCheck,
+	); err != nil {
+		return
+	}
+	t.Usage = fields.Usage
+	t.ResourcePath = fields.ResourcePath
+	t.ResourcePathName = fields.ResourcePathName
+	t.MethodName = fields.MethodName
+	return
+}
+
 // IsEmpty return true if it is empty
 func (t TraitRAML) IsEmpty() bool {
 	return t.Method.IsEmpty() &&
@@ -55,6 +55,13 @@ type TypeDeclaration struct {
 	// that annotation.
 	Annotations Annotations `yaml:",regexp:\\(.*\\)" json:"annotations,omitempty"`
 
+	// Annotations of scalar-valued nodes in value form keyed by the facet
+	// name, e.g. displayName and description.
+	ScalarAnnotations ScalarAnnotations `yaml:"-" json:"scalarAnnotations,omitempty"`
+
+	// The field used for check typo error of scalar-valued nodes in value form
+	ScalarTypoCheck scalarTypoCheck `yaml:"-" json:"-"`
+
 	// A map of additional, user-defined restrictions that will be inherited
 	// and applied by any extending subtype. See section User-defined Facets
 	// for more information.
@@ -64,6 +71,21 @@ type TypeDeclaration struct {
 	XML Unimplement `yaml:"xml" json:"xml,omitempty"`
 }
 
+// UnmarshalYAML unmarshal TypeDeclaration from YAML
+func (t *TypeDeclaration) UnmarshalYAML(unmarshaler func(interface{}) error) (err error) {
+	type plain TypeDeclaration
+	return unmarshalScalarAnnotations(
+		unmarshaler,
+		func() error { return unmarshaler((*plain)(t)) },
+		map[string]*string{
+			"displayName": &t.DisplayName,
+			"description": &t.Description,
+		},
+		&t.ScalarAnnotations,
+		&t.ScalarTypoCheck,
+	)
+}
+
 // IsEmpty return true if it is empty
 func (t *TypeDeclaration) IsEmpty() bool {
 	if t == nil {
@@ -81,6 +103,14 @@ func (t *TypeDeclaration) IsEmpty() bool {
 		t.XML.IsEmpty()
 }
 
+// allAnnotations return annotations of the type and its scalar-valued nodes,
+// used for nodes not walked by postProcess, e.g. properties
+func (t TypeDeclaration) allAnnotations() []Annotations {
+	return append([]Annotations{
+		t.Annotations,
+	}, t.ScalarAnnotations.all()...)
+}
+
 var _ checkAnnotation = TypeDeclaration{}
 
 func (t TypeDeclaration) checkAnnotation(conf PostProcessConfig) (err error) {
@@ -5,66 +5,183 @@ import (
 	"crypto/sha512"
 	"encoding/gob"
 	"fmt"
-	"os"
-	"path/filepath"
+	"sort"
+	"strings"
 )
 
+// Cache store encoded parsing results by key, used by parser config
+// CacheStore, implementations should be safe for concurrent use
+type Cache interface {
+	// Get return cached data of key, or ErrorCacheNotFound if not exist
+	Get(key string) (data []byte, err error)
+	// Set store data of key, least recently used entries MAY be evicted
+	Set(key string, data []byte) (err error)
+	// Stats return statistics of cached entries
+	Stats() (stats CacheStats, err error)
+	// Prune evict least recently used entries until total size <= maxBytes
+	Prune(maxBytes int64) (err error)
+	// Clear remove all cached entries
+	Clear() (err error)
+}
+
+// CacheStats statistics of cached entries
+type CacheStats struct {
+	Entries int64 `json:"entries"`
+	Bytes   int64 `json:"bytes"`
+}
+
+// cacheDependency file read in parsing, cache is invalidated if the
+// content of any dependency changed
+type cacheDependency struct {
+	// canonical location of file, or directory of project files
+	Location  string
+	Directory bool
+	Hash      [sha512.Size]byte
+}
+
+func (t cacheDependency) key() string {
+	if t.Directory {
+		return "directory:" + t.Location
+	}
+	return t.Location
+}
+
+// addDependency record the file read in parsing
+func (t *fileLoader) addDependency(dependency cacheDependency) {
+	if t.dependencies == nil {
+		t.dependencies = map[string]cacheDependency{}
+	}
+	t.dependencies[dependency.key()] = dependency
+}
+
+// listDependencies return recorded dependencies sorted by location
+func (t fileLoader) listDependencies() (dependencies []cacheDependency) {
+	for _, dependency := range t.dependencies {
+		dependencies = append(dependencies, dependency)
+	}
+	sort.Slice(dependencies, func(i, j int) bool {
+		return dependencies[i].key() < dependencies[j].key()
+	})
+	return
+}
+
+// checkDependency return true if the dependency is not changed
+func (t fileLoader) checkDependency(dependency cacheDependency) bool {
+	var hash [sha512.Size]byte
+	if dependency.Directory {
+		names, err := t.findRAMLFiles(dependency.Location)
+		if err != nil {
+			return false
+		}
+		hash = hashFileNames(names)
+	} else {
+		data, _, err := t.resolver.Resolve("", dependency.Location)
+		if err != nil {
+			return false
+		}
+		hash = sha512.Sum512(data)
+	}
+	return hash == dependency.Hash
+}
+
+func hashFileNames(names []string) [sha512.Size]byte {
+	return sha512.Sum512([]byte(strings.Join(names, "\n")))
+}
+
+// cacheConfig return the parser config which affects parsing result,
+// cache is invalidated if config changed
+func (t parserImpl) cacheConfig() string {
+	checkValueOptions := []string{}
+	for _, option := range t.checkValueOptions {
+		checkValueOptions = append(checkValueOptions, cacheOptionKey(option))
+	}
+	generateValueOptions := []string{}
+	for _, option := range t.generateValueOptions {
+		generateValueOptions = append(generateValueOptions, cacheOptionKey(option))
+	}
+	return fmt.Sprintf(
+		"%v|%q|%v|%q|%v|%v|%v|%v|%#v|%v|%v|%v|%v",
+		t.checkRAMLVersion,
+		checkValueOptions,
+		t.errorTraceDistance,
+		generateValueOptions,
+		t.ignoreUnusedAnnotation,
+		t.ignoreUnusedLibrary,
+		t.ignoreUnusedTrait,
+		t.ignoreUnusedType,
+		t.librarySearchPaths,
+		t.maxIncludeBytes,
+		t.maxIncludeDepth,
+		t.maxYAMLAliasExpansion,
+		t.sandboxDirectory,
+	)
+}
+
+// cacheOptionKey return the option with concrete type, e.g.
+// "parser.CheckValueOptionAllowArrayToBeNull=true"
+func cacheOptionKey(option interface{}) string {
+	return fmt.Sprintf("%T=%v", option, option)
+}
+
+// loadFromCache load RootDocument of filePath from cache, cache is valid
+// if package version, parser config and all dependencies are not changed,
+// saveFunc is returned to save the parsing result with dependencies
+// recorded by loader if cache is invalid
 func loadFromCache(
 	filePath string,
-	fileData []byte,
-	cacheDirectory string,
+	cache Cache,
+	config string,
+	loader *fileLoader,
 ) (
 	saveFunc func(RootDocument),
 	rootdoc RootDocument,
 	err error,
 ) {
 	type cacheStruct struct {
-		Hash    [sha512.Size]byte
-		RootDoc RootDocument
+		Version      string
+		Config       string
+		Dependencies []cacheDependency
+		RootDoc      RootDocument
 	}
 
 	hashpath := sha512.Sum512([]byte(filePath))
-	hashfile := sha512.Sum512(fileData)
-	cachename := filepath.Join(
-		cacheDirectory,
-		fmt.Sprintf("%x", hashpath[0:16]),
-	)
+	cachekey := fmt.Sprintf("%x", hashpath[0:16])
 
 	saveFunc = func(saveRootDoc RootDocument) {
-		if err = os.MkdirAll(cacheDirectory, 0700); err != nil {
-			return
-		}
-
-		var saveFile *os.File
-		if saveFile, err = os.Create(cachename); err != nil {
-			return
-		}
-		defer saveFile.Close()
-
-		enc := gob.NewEncoder(saveFile)
+		buffer := &bytes.Buffer{}
+		enc := gob.NewEncoder(buffer)
 		if err = enc.Encode(&cacheStruct{
-			Hash:    hashfile,
-			RootDoc: saveRootDoc,
+			Version:      Version,
+			Config:       config,
+			Dependencies: loader.listDependencies(),
+			RootDoc:      saveRootDoc,
 		}); err != nil {
 			return
 		}
+		if err = cache.Set(cachekey, buffer.Bytes()); err != nil {
+			return
+		}
 	}
 
-	var cachefile *os.File
-	if cachefile, err = os.Open(cachename); err != nil {
+	data, err := cache.Get(cachekey)
+	if err != nil {
 		return saveFunc, rootdoc, ErrorCacheNotFound.New(err)
 	}
-	defer cachefile.Close()
 
-	dec := gob.NewDecoder(cachefile)
+	dec := gob.NewDecoder(bytes.NewReader(data))
 	cached := cacheStruct{}
 	if err = dec.Decode(&cached); err != nil {
 		return saveFunc, rootdoc, ErrorCacheNotFound.New(err)
 	}
 
-	if bytes.Equal(hashfile[:], cached.Hash[:]) {
-		return nil, cached.RootDoc, nil
+	if cached.Version != Version || cached.Config != config || len(cached.Dependencies) < 1 {
+		return saveFunc, rootdoc, ErrorCacheNotFound.New(nil)
+	}
+	for _, dependency := range cached.Dependencies {
+		if !loader.checkDependency(dependency) {
+			return saveFunc, rootdoc, ErrorCacheNotFound.New(nil)
+		}
 	}
 
-	return saveFunc, rootdoc, ErrorCacheNotFound.New(nil)
+	return nil, cached.RootDoc, nil
 }
@@ -0,0 +1,236 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// NewFSCache return Cache which stores entries as files in directory,
+// writing is atomic and locked between processes, least recently used
+// entries are evicted if total size exceeds maxBytes, set 0 to disable
+func NewFSCache(directory string, maxBytes int64) Cache {
+	return fsCache{
+		directory: directory,
+		maxBytes:  maxBytes,
+	}
+}
+
+const (
+	// files with the prefix in cache directory are not entries
+	fsCacheInternalPrefix = "."
+	fsCacheLockName       = ".lock"
+	fsCacheTempPrefix     = ".tmp-"
+	fsCacheLockTimeout    = 10 * time.Second
+	fsCacheLockRetry      = 10 * time.Millisecond
+	// lock file is removed if older than the age, e.g. process killed
+	fsCacheStaleLockAge = time.Minute
+)
+
+type fsCache struct {
+	directory string
+	maxBytes  int64
+}
+
+func (t fsCache) Get(key string) (data []byte, err error) {
+	path := filepath.Join(t.directory, key)
+	if data, err = ioutil.ReadFile(path); err != nil {
+		return nil, ErrorCacheNotFound.New(err)
+	}
+	// modification time is the access time for evicting
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	return
+}
+
+func (t fsCache) Set(key string, data []byte) (err error) {
+	if err = os.MkdirAll(t.directory, 0700); err != nil {
+		return
+	}
+
+	tempFile, err := ioutil.TempFile(t.directory, fsCacheTempPrefix)
+	if err != nil {
+		return
+	}
+	defer os.Remove(tempFile.Name())
+	if _, err = tempFile.Write(data); err != nil {
+		tempFile.Close()
+		return
+	}
+	if err = tempFile.Close(); err != nil {
+		return
+	}
+
+	unlock, err := t.lock()
+	if err != nil {
+		return
+	}
+	defer unlock()
+
+	if err = os.Rename(tempFile.Name(), filepath.Join(t.directory, key)); err != nil {
+		return
+	}
+	if t.maxBytes > 0 {
+		return t.prune(t.maxBytes)
+	}
+	return
+}
+
+func (t fsCache) Stats() (stats CacheStats, err error) {
+	entries, err := t.entries()
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		stats.Entries++
+		stats.Bytes += entry.Size()
+	}
+	return
+}
+
+func (t fsCache) Prune(maxBytes int64) (err error) {
+	unlock, err := t.lock()
+	if err != nil {
+		return
+	}
+	defer unlock()
+	return t.prune(maxBytes)
+}
+
+func (t fsCache) Clear() (err error) {
+	unlock, err := t.lock()
+	if err != nil {
+		return
+	}
+	defer unlock()
+	return t.prune(0)
+}
+
+// prune remove least recently used entries until total size <= maxBytes,
+// the cache should be locked by caller
+func (t fsCache) prune(maxBytes int64) (err error) {
+	entries, err := t.entries()
+	if err != nil {
+		return
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ModTime().After(entries[j].ModTime())
+	})
+
+	total := int64(0)
+	for _, entry := range entries {
+		total += entry.Size()
+		if total <= maxBytes {
+			continue
+		}
+		if err = os.Remove(filepath.Join(t.directory, entry.Name())); err != nil && !os.IsNotExist(err) {
+			return
+		}
+	}
+	return nil
+}
+
+// entries return file info of all cache entries
+func (t fsCache) entries() (entries []os.FileInfo, err error) {
+	infos, err := ioutil.ReadDir(t.directory)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return
+	}
+	for _, info := range infos {
+		if info.IsDir() || strings.HasPrefix(info.Name(), fsCacheInternalPrefix) {
+			continue
+		}
+		entries = append(entries, info)
+	}
+	return
+}
+
+// lock cache directory between processes by creating lock file exclusively,
+// the lock file records the owner which is checked again after creating
+// because stale lock may be broken by other processes at the same time,
+// return function to release the lock
+func (t fsCache) lock() (unlock func(), err error) {
+	if err = os.MkdirAll(t.directory, 0700); err != nil {
+		return
+	}
+
+	lockPath := filepath.Join(t.directory, fsCacheLockName)
+	owner := newFSCacheLockOwner()
+	deadline := time.Now().Add(fsCacheLockTimeout)
+	for {
+		if err = createFSCacheLock(lockPath, owner); err == nil {
+			if current, errRead := ioutil.ReadFile(lockPath); errRead == nil && string(current) == owner {
+				return func() { removeFSCacheLock(lockPath, owner) }, nil
+			}
+		} else if os.IsExist(err) {
+			breakStaleFSCacheLock(lockPath, owner)
+		} else {
+			return
+		}
+		if time.Now().After(deadline) {
+			return nil, ErrorCacheLocked1.New(nil, lockPath)
+		}
+		time.Sleep(fsCacheLockRetry)
+	}
+}
+
+var fsCacheLockCount int64
+
+// newFSCacheLockOwner return unique owner of lock between processes and
+// goroutines
+func newFSCacheLockOwner() string {
+	count := atomic.AddInt64(&fsCacheLockCount, 1)
+	return fmt.Sprintf("%d-%d-%d", os.Getpid(), time.Now().UnixNano(), count)
+}
+
+func createFSCacheLock(lockPath string, owner string) (err error) {
+	lockFile, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	if _, err = lockFile.WriteString(owner); err != nil {
+		lockFile.Close()
+		return
+	}
+	return lockFile.Close()
+}
+
+// removeFSCacheLock remove lock file only if it is still owned by owner
+func removeFSCacheLock(lockPath string, owner string) {
+	if current, err := ioutil.ReadFile(lockPath); err == nil && string(current) == owner {
+		os.Remove(lockPath)
+	}
+}
+
+// breakStaleFSCacheLock remove lock file older than fsCacheStaleLockAge,
+// e.g. process killed, the lock file is renamed aside before removing, and
+// restored if it is not the checked stale one
+func breakStaleFSCacheLock(lockPath string, owner string) {
+	// read owner before checking age, new lock file is never stale
+	stale, err := ioutil.ReadFile(lockPath)
+	if err != nil {
+		return
+	}
+	if info, err := os.Stat(lockPath); err != nil || time.Since(info.ModTime()) <= fsCacheStaleLockAge {
+		return
+	}
+
+	asidePath := lockPath + "-" + owner
+	if err = os.Rename(lockPath, asidePath); err != nil {
+		return
+	}
+	if current, err := ioutil.ReadFile(asidePath); err == nil && !bytes.Equal(current, stale) {
+		// locked by other process after checked
+		_ = os.Link(asidePath, lockPath)
+	}
+	os.Remove(asidePath)
+}
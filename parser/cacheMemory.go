@@ -0,0 +1,104 @@
+package parser
+
+import "sync"
+
+// NewMemoryCache return Cache which stores entries in memory of current
+// process, least recently used entries are evicted if total size exceeds
+// maxBytes, set 0 to disable
+func NewMemoryCache(maxBytes int64) Cache {
+	return &memoryCache{
+		maxBytes: maxBytes,
+		entries:  map[string]*memoryCacheEntry{},
+	}
+}
+
+type memoryCache struct {
+	lock     sync.Mutex
+	maxBytes int64
+	entries  map[string]*memoryCacheEntry
+	// increased by each access, used as access time for evicting
+	clock int64
+}
+
+type memoryCacheEntry struct {
+	data   []byte
+	access int64
+}
+
+func (t *memoryCache) Get(key string) (data []byte, err error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	entry, ok := t.entries[key]
+	if !ok {
+		return nil, ErrorCacheNotFound.New(nil)
+	}
+	t.clock++
+	entry.access = t.clock
+	return append([]byte{}, entry.data...), nil
+}
+
+func (t *memoryCache) Set(key string, data []byte) (err error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.clock++
+	t.entries[key] = &memoryCacheEntry{
+		data:   append([]byte{}, data...),
+		access: t.clock,
+	}
+	if t.maxBytes > 0 {
+		t.prune(t.maxBytes)
+	}
+	return nil
+}
+
+func (t *memoryCache) Stats() (stats CacheStats, err error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for _, entry := range t.entries {
+		stats.Entries++
+		stats.Bytes += int64(len(entry.data))
+	}
+	return
+}
+
+func (t *memoryCache) Prune(maxBytes int64) (err error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.prune(maxBytes)
+	return nil
+}
+
+func (t *memoryCache) Clear() (err error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.entries = map[string]*memoryCacheEntry{}
+	return nil
+}
+
+// prune remove least recently used entries until total size <= maxBytes,
+// the cache should be locked by caller
+func (t *memoryCache) prune(maxBytes int64) {
+	total := int64(0)
+	for _, entry := range t.entries {
+		total += int64(len(entry.data))
+	}
+	for total > maxBytes {
+		oldestKey := ""
+		var oldest *memoryCacheEntry
+		for key, entry := range t.entries {
+			if oldest == nil || entry.access < oldest.access {
+				oldestKey, oldest = key, entry
+			}
+		}
+		if oldest == nil {
+			return
+		}
+		delete(t.entries, oldestKey)
+		total -= int64(len(oldest.data))
+	}
+}
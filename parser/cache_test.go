@@ -0,0 +1,124 @@
+package parser
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testCache(t *testing.T, cache Cache) {
+	require := require.New(t)
+	require.NotNil(require)
+
+	_, err := cache.Get("a")
+	require.True(ErrorCacheNotFound.Match(err))
+
+	require.NoError(cache.Set("a", []byte("aaaa")))
+	require.NoError(cache.Set("b", []byte("bbbb")))
+	data, err := cache.Get("a")
+	require.NoError(err)
+	require.Equal([]byte("aaaa"), data)
+
+	stats, err := cache.Stats()
+	require.NoError(err)
+	require.Equal(CacheStats{Entries: 2, Bytes: 8}, stats)
+
+	// "b" is least recently used
+	require.NoError(cache.Prune(6))
+	_, err = cache.Get("b")
+	require.True(ErrorCacheNotFound.Match(err))
+	_, err = cache.Get("a")
+	require.NoError(err)
+
+	require.NoError(cache.Clear())
+	stats, err = cache.Stats()
+	require.NoError(err)
+	require.Equal(CacheStats{}, stats)
+}
+
+func Test_MemoryCache(t *testing.T) {
+	require := require.New(t)
+	require.NotNil(require)
+
+	testCache(t, NewMemoryCache(0))
+
+	cache := NewMemoryCache(10)
+	require.NoError(cache.Set("a", []byte("aaaa")))
+	require.NoError(cache.Set("b", []byte("bbbb")))
+	require.NoError(cache.Set("c", []byte("cccc")))
+	_, err := cache.Get("a")
+	require.True(ErrorCacheNotFound.Match(err))
+}
+
+func Test_FSCache(t *testing.T) {
+	require := require.New(t)
+	require.NotNil(require)
+
+	tempdir, err := ioutil.TempDir("", "go-raml-parser")
+	require.NoError(err)
+	defer os.RemoveAll(tempdir)
+
+	cache := NewFSCache(tempdir, 0).(fsCache)
+	testCache(t, cache)
+
+	unlock, err := cache.lock()
+	require.NoError(err)
+	_, err = os.Stat(filepath.Join(tempdir, fsCacheLockName))
+	require.NoError(err)
+	unlock()
+	_, err = os.Stat(filepath.Join(tempdir, fsCacheLockName))
+	require.True(os.IsNotExist(err))
+
+	// stale lock file left by killed process
+	require.NoError(ioutil.WriteFile(filepath.Join(tempdir, fsCacheLockName), nil, 0600))
+	stale := time.Now().Add(-2 * fsCacheStaleLockAge)
+	require.NoError(os.Chtimes(filepath.Join(tempdir, fsCacheLockName), stale, stale))
+	require.NoError(cache.Set("a", []byte("aaaa")))
+
+	files, err := ioutil.ReadDir(tempdir)
+	require.NoError(err)
+	require.Len(files, 1)
+	require.Equal("a", files[0].Name())
+
+	// lock of other owner is neither broken nor released
+	lockPath := filepath.Join(tempdir, fsCacheLockName)
+	unlock, err = cache.lock()
+	require.NoError(err)
+	breakStaleFSCacheLock(lockPath, newFSCacheLockOwner())
+	removeFSCacheLock(lockPath, newFSCacheLockOwner())
+	_, err = os.Stat(lockPath)
+	require.NoError(err)
+	unlock()
+	_, err = os.Stat(lockPath)
+	require.True(os.IsNotExist(err))
+
+	// stale lock broken by concurrent lockers at the same time
+	require.NoError(ioutil.WriteFile(lockPath, nil, 0600))
+	require.NoError(os.Chtimes(lockPath, stale, stale))
+	holders := int32(0)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock, err := cache.lock()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if atomic.AddInt32(&holders, 1) > 1 {
+				t.Error("cache is locked by multiple owners")
+			}
+			time.Sleep(fsCacheLockRetry)
+			atomic.AddInt32(&holders, -1)
+			unlock()
+		}()
+	}
+	wg.Wait()
+}
@@ -2,8 +2,11 @@ package parser
 
 import (
 	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/tsaikd/KDGoLib/errutil"
+	"github.com/tsaikd/go-raml-parser/parser/parserConfig"
 )
 
 // CheckValueOption for changing CheckValueAPIType behavior
@@ -23,11 +26,23 @@ type CheckValueOptionAllowArrayToBeNull bool
 // default: false
 type CheckValueOptionAllowRequiredPropertyToBeEmpty bool
 
-// CheckValueAPIType check value is valid for apiType
+// CheckValueOptionAllFailures return all failures in ValueCheckError instead
+// of the first failure
+// default: false
+type CheckValueOptionAllFailures bool
+
+// CheckValueAPIType check value is valid for apiType, failures carry the
+// JSON pointer of the invalid value, e.g. "/orders/3/items/0/price"
 func CheckValueAPIType(apiType APIType, value Value, options ...CheckValueOption) (err error) {
+	return exportError(checkValueAPITypeWithOptions(apiType, value, options...))
+}
+
+// checkValueAPITypeWithOptions return the internal error of CheckValueAPIType
+func checkValueAPITypeWithOptions(apiType APIType, value Value, options ...CheckValueOption) (err error) {
 	allowIntegerToBeNumber := CheckValueOptionAllowIntegerToBeNumber(false)
 	allowArrayToBeNull := CheckValueOptionAllowArrayToBeNull(false)
 	allowRequiredPropertyToBeEmpty := CheckValueOptionAllowRequiredPropertyToBeEmpty(false)
+	allFailures := CheckValueOptionAllFailures(false)
 
 	for _, option := range options {
 		switch optval := option.(type) {
@@ -37,24 +52,43 @@ func CheckValueAPIType(apiType APIType, value Value, options ...CheckValueOption
 			allowArrayToBeNull = optval
 		case CheckValueOptionAllowRequiredPropertyToBeEmpty:
 			allowRequiredPropertyToBeEmpty = optval
+		case CheckValueOptionAllFailures:
+			allFailures = optval
 		}
 	}
 
 	return checkValueAPIType(
 		apiType,
 		value,
+		nil,
 		allowIntegerToBeNumber,
 		allowArrayToBeNull,
 		allowRequiredPropertyToBeEmpty,
+		allFailures,
 	)
 }
 
+// checkValueOptionsOf return CheckValueOptions of parser config
+func checkValueOptionsOf(conf PostProcessConfig) []CheckValueOption {
+	value, err := conf.Parser().Get(parserConfig.CheckValueOptions)
+	if err != nil {
+		return []CheckValueOption{}
+	}
+	if options, ok := value.([]CheckValueOption); ok {
+		return options
+	}
+	return []CheckValueOption{}
+}
+
+// checkValueAPIType check value at path of the checked value
 func checkValueAPIType(
 	apiType APIType,
 	value Value,
+	path []string,
 	allowIntegerToBeNumber CheckValueOptionAllowIntegerToBeNumber,
 	allowArrayToBeNull CheckValueOptionAllowArrayToBeNull,
 	allowRequiredPropertyToBeEmpty CheckValueOptionAllowRequiredPropertyToBeEmpty,
+	allFailures CheckValueOptionAllFailures,
 ) (err error) {
 	if value.IsEmpty() {
 		// no need to check if value is empty
@@ -64,26 +98,45 @@ func checkValueAPIType(
 	if apiType.IsArray {
 		if value.Type != TypeArray {
 			if !allowArrayToBeNull || value.Type != TypeNull {
-				return ErrorPropertyTypeMismatch2.New(nil, apiType.Type, value.Type)
+				return newTypeMismatchError(ErrorPropertyTypeMismatch2, valuePointerError(path), path, apiType.Type, value.Type, apiType.Type, value.Type)
 			}
 		}
 
 		elemType := apiType
 		elemType.IsArray = false
 		for i, elemValue := range value.Array {
-			if err = checkValueAPIType(
+			elemPath := appendValuePath(path, strconv.Itoa(i))
+			elemErr := checkValueAPIType(
 				elemType,
 				*elemValue,
+				elemPath,
 				allowIntegerToBeNumber,
 				allowArrayToBeNull,
 				allowRequiredPropertyToBeEmpty,
-			); err != nil {
-				switch errutil.FactoryOf(err) {
-				case ErrorPropertyTypeMismatch2:
-					return ErrorArrayElementTypeMismatch3.New(nil, i, elemType.Type, elemValue.Type)
+				allFailures,
+			)
+			if elemErr == nil {
+				continue
+			}
+			switch errutil.FactoryOf(elemErr) {
+			case ErrorPropertyTypeMismatch2:
+				expected := elemType.BaseType
+				if expected == "" {
+					expected = elemType.Type
 				}
-				return
+				elemErr = newTypeMismatchError(
+					ErrorArrayElementTypeMismatch3,
+					valuePointerError(elemPath),
+					elemPath,
+					expected,
+					elemValue.Type,
+					i, elemType.Type, elemValue.Type,
+				)
+			}
+			if !allFailures {
+				return elemErr
 			}
+			err = appendValueFailure(err, elemErr)
 		}
 		return
 	}
@@ -93,7 +146,7 @@ func checkValueAPIType(
 		if apiType.NativeType == value.Type {
 			return nil
 		}
-		return ErrorPropertyTypeMismatch2.New(nil, apiType.Type, value.Type)
+		return newTypeMismatchError(ErrorPropertyTypeMismatch2, valuePointerError(path), path, apiType.Type, value.Type, apiType.Type, value.Type)
 	case TypeInteger:
 		if apiType.NativeType == value.Type {
 			return nil
@@ -106,7 +159,7 @@ func checkValueAPIType(
 				}
 			}
 		}
-		return ErrorPropertyTypeMismatch2.New(nil, apiType.Type, value.Type)
+		return newTypeMismatchError(ErrorPropertyTypeMismatch2, valuePointerError(path), path, apiType.Type, value.Type, apiType.Type, value.Type)
 	case TypeNumber:
 		if apiType.NativeType == value.Type {
 			return nil
@@ -117,7 +170,12 @@ func checkValueAPIType(
 				return nil
 			}
 		}
-		return ErrorPropertyTypeMismatch2.New(nil, apiType.Type, value.Type)
+		return newTypeMismatchError(ErrorPropertyTypeMismatch2, valuePointerError(path), path, apiType.Type, value.Type, apiType.Type, value.Type)
+	case TypeDateOnly, TypeTimeOnly, TypeDateTimeOnly, TypeDateTime:
+		if value.Type == TypeString {
+			return nil
+		}
+		return newTypeMismatchError(ErrorPropertyTypeMismatch2, valuePointerError(path), path, apiType.Type, value.Type, apiType.Type, value.Type)
 	case TypeFile:
 		// no type check for file type
 		return nil
@@ -130,38 +188,46 @@ func checkValueAPIType(
 		switch value.Type {
 		case TypeObject, TypeNull:
 		default:
-			return ErrorPropertyTypeMismatch2.New(nil, apiType.Type, value.Type)
+			return newTypeMismatchError(ErrorPropertyTypeMismatch2, valuePointerError(path), path, apiType.Type, value.Type, apiType.Type, value.Type)
 		}
 
-		for _, property := range apiType.Properties.Slice() {
-			if err = checkPropertyRequired(
+		for _, property := range apiType.resolved().Properties.Slice() {
+			propertyErr := checkPropertyRequired(
 				*property,
 				value,
+				path,
 				allowArrayToBeNull,
 				allowRequiredPropertyToBeEmpty,
 				apiType,
-			); err != nil {
-				return err
+			)
+			if propertyErr == nil {
+				propertyErr = checkPropertyValue(
+					*property,
+					value,
+					path,
+					allowIntegerToBeNumber,
+					allowArrayToBeNull,
+					allowRequiredPropertyToBeEmpty,
+					allFailures,
+				)
 			}
-
-			if err = checkPropertyValue(
-				*property,
-				value,
-				allowIntegerToBeNumber,
-				allowArrayToBeNull,
-				allowRequiredPropertyToBeEmpty,
-			); err != nil {
-				return err
+			if propertyErr == nil {
+				continue
+			}
+			if !allFailures {
+				return propertyErr
 			}
+			err = appendValueFailure(err, propertyErr)
 		}
 	}
 
-	return nil
+	return
 }
 
 func checkPropertyRequired(
 	property Property,
 	parent Value,
+	parentPath []string,
 	allowArrayToBeNull CheckValueOptionAllowArrayToBeNull,
 	allowRequiredPropertyToBeEmpty CheckValueOptionAllowRequiredPropertyToBeEmpty,
 	apiType APIType, // only used for error message
@@ -173,19 +239,20 @@ func checkPropertyRequired(
 		return nil
 	}
 
+	path := appendValuePath(parentPath, property.Name)
 	switch parent.Type {
 	case TypeNull:
-		return ErrorRequiredProperty2.New(nil, property.Name, apiType.Type)
+		return newRequiredPropertyError(path, property.Name, apiType.Type)
 	case TypeObject:
 		value := parent.Map[property.Name]
 		if value == nil {
-			return ErrorRequiredProperty2.New(nil, property.Name, apiType.Type)
+			return newRequiredPropertyError(path, property.Name, apiType.Type)
 		}
 		if !bool(allowRequiredPropertyToBeEmpty) {
 			switch value.Type {
 			case TypeString, TypeArray, TypeObject, TypeBinary:
 				if value.IsZero() {
-					return ErrorRequiredProperty2.New(nil, property.Name, apiType.Type)
+					return newRequiredPropertyError(path, property.Name, apiType.Type)
 				}
 			}
 		}
@@ -198,9 +265,11 @@ func checkPropertyRequired(
 func checkPropertyValue(
 	property Property,
 	parent Value,
+	parentPath []string,
 	allowIntegerToBeNumber CheckValueOptionAllowIntegerToBeNumber,
 	allowArrayToBeNull CheckValueOptionAllowArrayToBeNull,
 	allowRequiredPropertyToBeEmpty CheckValueOptionAllowRequiredPropertyToBeEmpty,
+	allFailures CheckValueOptionAllFailures,
 ) (err error) {
 	value := parent.Map[property.Name]
 	if value == nil {
@@ -211,18 +280,37 @@ func checkPropertyValue(
 		return nil
 	}
 
+	path := appendValuePath(parentPath, property.Name)
 	if err = checkValueAPIType(
 		property.APIType,
 		*value,
+		path,
 		allowIntegerToBeNumber,
 		allowArrayToBeNull,
 		allowRequiredPropertyToBeEmpty,
+		allFailures,
 	); err != nil {
 		switch errutil.FactoryOf(err) {
 		case ErrorPropertyTypeMismatch2:
-			return ErrorPropertyTypeMismatch3.New(nil, property.Name, property.Type, value.Type)
+			return newTypeMismatchError(
+				ErrorPropertyTypeMismatch3,
+				valuePointerError(path),
+				path,
+				property.Type,
+				value.Type,
+				property.Name, property.Type, value.Type,
+			)
 		case ErrorArrayElementTypeMismatch3:
-			return ErrorPropertyTypeMismatch1.New(err, property.Name)
+			// the element error carries the full path
+			elemErr := err.(*TypeMismatchError)
+			return newTypeMismatchError(
+				ErrorPropertyTypeMismatch1,
+				err,
+				elemErr.Path,
+				elemErr.Expected,
+				elemErr.Actual,
+				property.Name,
+			)
 		}
 		return err
 	}
@@ -230,7 +318,116 @@ func checkPropertyValue(
 	return nil
 }
 
+// valuePointerError return error showing JSON pointer of path, nil if path is
+// the checked value
+func valuePointerError(path []string) error {
+	if len(path) < 1 {
+		return nil
+	}
+	return ErrorValuePointer1.New(nil, valuePointer(path))
+}
+
+// valuePointer return JSON pointer of path, RFC 6901
+func valuePointer(path []string) string {
+	pointer := ""
+	for _, token := range path {
+		pointer += "/" + escapeJSONPointer(token)
+	}
+	return pointer
+}
+
+// appendValuePath return a new path with elem appended
+func appendValuePath(path []string, elem string) []string {
+	return append(append([]string{}, path...), elem)
+}
+
+// appendValueFailure append failure to err, return ValueCheckError if more
+// than one failure
+func appendValueFailure(err error, failure error) error {
+	failures := []error{}
+	for _, elem := range []error{err, failure} {
+		switch elem := elem.(type) {
+		case nil:
+		case *ValueCheckError:
+			failures = append(failures, elem.Failures...)
+		default:
+			failures = append(failures, elem)
+		}
+	}
+	if len(failures) == 1 {
+		return failures[0]
+	}
+	return newValueCheckError(failures)
+}
+
+// checkValueUnionAPIType check value is valid for apiType which MAY be a
+// union type, e.g. "string | Limit[]", value is valid if any member type is
+// valid, member types are resolved by library
+func checkValueUnionAPIType(library Library, apiType APIType, value Value, options ...CheckValueOption) (err error) {
+	return checkValueUnion(library, apiType, value, map[string]bool{}, options...)
+}
+
+func checkValueUnion(library Library, apiType APIType, value Value, visited map[string]bool, options ...CheckValueOption) (err error) {
+	members := splitUnionType(apiType.Type)
+	if len(members) < 2 {
+		return checkValueAPITypeWithOptions(apiType, value, options...)
+	}
+	if visited[apiType.Type] {
+		// cyclic union type, no more check
+		return nil
+	}
+	visited[apiType.Type] = true
+	defer delete(visited, apiType.Type)
+
+	for _, member := range members {
+		var memberType APIType
+		if memberType, err = unionMemberAPIType(library, member); err != nil {
+			return
+		}
+		if checkValueUnion(library, memberType, value, visited, options...) == nil {
+			return nil
+		}
+	}
+	return newTypeMismatchError(ErrorPropertyTypeMismatch2, nil, nil, apiType.Type, value.Type, apiType.Type, value.Type)
+}
+
+// splitUnionType return member type names of union type expression,
+// parenthesized expressions are not supported
+func splitUnionType(name string) (members []string) {
+	if strings.ContainsAny(name, "()") {
+		return []string{name}
+	}
+	for _, member := range strings.Split(name, "|") {
+		members = append(members, strings.TrimSpace(member))
+	}
+	return
+}
+
+// unionMemberAPIType return APIType of union member type name
+func unionMemberAPIType(library Library, name string) (apiType APIType, err error) {
+	baseType, isArray := IsArrayType(name)
+	switch baseType {
+	case TypeBoolean, TypeInteger, TypeNumber, TypeString, TypeFile, TypeObject,
+		TypeDateOnly, TypeTimeOnly, TypeDateTimeOnly, TypeDateTime:
+		apiType = *NewAPIType()
+		apiType.setType(name)
+		return
+	}
+
+	typ, err := library.GetType(baseType)
+	if err != nil {
+		return
+	}
+	apiType = *typ
+	if isArray {
+		apiType.Type = name
+		apiType.IsArray = true
+	}
+	return
+}
+
 func isInlineAPIType(apiType APIType) bool {
-	regValidType := regexp.MustCompile(`^[\w]+(\[\])?$`)
+	// type name MAY be prefixed by library names, e.g. "lib.Type[]"
+	regValidType := regexp.MustCompile(`^[\w]+(\.[\w]+)*(\[\])?$`)
 	return !regValidType.MatchString(apiType.Type)
 }
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
@@ -296,6 +297,149 @@ properties:
 	))
 }
 
+func Test_CheckValueAPIType_ErrorAs(t *testing.T) {
+	require := require.New(t)
+	require.NotNil(require)
+
+	apiType := getAPITypeFromString(`
+type: object
+properties:
+    level1:
+        type: object
+        properties:
+            tags: string[]
+            text: string
+	`)
+
+	err := testCheckValueAPIType(apiType,
+		map[string]interface{}{
+			"level1": map[string]interface{}{
+				"tags": []interface{}{"a", 1},
+				"text": "test string",
+			},
+		},
+	)
+	require.True(ErrorPropertyTypeMismatch1.Match(err))
+	mismatchErr := &TypeMismatchError{}
+	require.True(errors.As(err, &mismatchErr))
+	require.Equal([]string{"level1", "tags", "1"}, mismatchErr.Path)
+	require.Equal("string", mismatchErr.Expected)
+	require.Equal(TypeInteger, mismatchErr.Actual)
+
+	err = testCheckValueAPIType(apiType,
+		map[string]interface{}{
+			"level1": map[string]interface{}{
+				"tags": []interface{}{"a"},
+			},
+		},
+	)
+	require.True(ErrorRequiredProperty2.Match(err))
+	requiredErr := &RequiredPropertyError{}
+	require.True(errors.As(err, &requiredErr))
+	require.Equal([]string{"level1", "text"}, requiredErr.Path)
+	require.Equal("text", requiredErr.Property)
+	require.False(errors.As(err, &mismatchErr))
+}
+
+func Test_CheckValueAPIType_Pointer(t *testing.T) {
+	require := require.New(t)
+	require.NotNil(require)
+
+	parser := NewParser()
+	require.NotNil(parser)
+
+	rootdoc, err := parser.ParseData([]byte(strings.TrimSpace(`
+#%RAML 1.0
+types:
+    Item:
+        type: object
+        properties:
+            name: string
+            price: number
+    Order:
+        type: object
+        properties:
+            items: Item[]
+    Request:
+        type: object
+        properties:
+            orders: Order[]
+	`)), ".")
+	require.NoError(err)
+	apiType := *rootdoc.Types["Request"]
+
+	order := func(price interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"items": []interface{}{
+				map[string]interface{}{
+					"name":  "item",
+					"price": price,
+				},
+			},
+		}
+	}
+
+	err = testCheckValueAPIType(apiType,
+		map[string]interface{}{
+			"orders": []interface{}{order(1.5), order(2.5), order(3.5), order("free")},
+		},
+	)
+	require.Error(err)
+	require.Contains(err.Error(), "at value /orders/3/items/0/price")
+	mismatchErr := &TypeMismatchError{}
+	require.True(errors.As(err, &mismatchErr))
+	require.Equal("/orders/3/items/0/price", mismatchErr.Pointer())
+	require.Equal(TypeNumber, mismatchErr.Expected)
+	require.Equal(TypeString, mismatchErr.Actual)
+
+	err = testCheckValueAPIType(apiType,
+		map[string]interface{}{
+			"orders": []interface{}{
+				order("free"),
+				map[string]interface{}{
+					"items": []interface{}{
+						map[string]interface{}{
+							"price": 1.5,
+						},
+					},
+				},
+				order(true),
+			},
+		},
+		CheckValueOptionAllFailures(true),
+	)
+	require.True(ErrorValueCheckFailed2.Match(err))
+	valueErr := &ValueCheckError{}
+	require.True(errors.As(err, &valueErr))
+	require.Len(valueErr.Failures, 3)
+	pointers := []string{}
+	for _, failure := range valueErr.Failures {
+		switch failure := failure.(type) {
+		case *TypeMismatchError:
+			pointers = append(pointers, failure.Pointer())
+		case *RequiredPropertyError:
+			pointers = append(pointers, failure.Pointer())
+		}
+	}
+	require.Equal([]string{
+		"/orders/0/items/0/price",
+		"/orders/1/items/0/name",
+		"/orders/2/items/0/price",
+	}, pointers)
+	requiredErr := &RequiredPropertyError{}
+	require.True(errors.As(err, &requiredErr))
+	require.Equal("name", requiredErr.Property)
+
+	// single failure is returned as is
+	err = testCheckValueAPIType(apiType,
+		map[string]interface{}{
+			"orders": []interface{}{order(1.5), order("free")},
+		},
+		CheckValueOptionAllFailures(true),
+	)
+	require.True(ErrorPropertyTypeMismatch3.Match(err))
+}
+
 func Test_CheckValueAPIType_CustomType(t *testing.T) {
 	require := require.New(t)
 	require.NotNil(require)
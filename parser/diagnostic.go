@@ -0,0 +1,241 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/tsaikd/KDGoLib/errutil"
+	"github.com/tsaikd/go-raml-parser/parser/parserConfig"
+)
+
+// Severity of Diagnostic
+type Severity int
+
+// List all valid severity
+const (
+	// SeverityError parsing result is invalid
+	SeverityError Severity = iota
+	// SeverityWarning parsing result is valid but might be unexpected,
+	// e.g. unused traits, not blocking the returned RootDocument
+	SeverityWarning
+	// SeverityInfo hint for RAML authors
+	SeverityInfo
+)
+
+func (t Severity) String() string {
+	switch t {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "info"
+	default:
+		return fmt.Sprintf("severity(%d)", int(t))
+	}
+}
+
+// MarshalJSON marshal severity as string
+func (t Severity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// Diagnostic a problem found in parsing
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	// stable code of the problem, e.g. "type-undefined"
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// source position of the node causing problem, zero if unknown
+	Position Position `json:"position,omitempty"`
+	// the original error
+	Err error `json:"-"`
+}
+
+func (t Diagnostic) String() string {
+	text := fmt.Sprintf("%s: %s [%s]", t.Severity, t.Message, t.Code)
+	if t.Position.IsZero() {
+		return text
+	}
+	return t.Position.String() + ": " + text
+}
+
+// newDiagnostic create Diagnostic of err
+func newDiagnostic(severity Severity, err error) Diagnostic {
+	diagnostic := Diagnostic{
+		Severity: severity,
+		Code:     DiagnosticCode(err),
+		Err:      exportError(err),
+	}
+	diagnostic.Message = err.Error()
+	if position, ok := ErrorPosition(err); ok {
+		// position is the oldest parent of error chain
+		diagnostic.Position = position
+		diagnostic.Message = strings.TrimSuffix(diagnostic.Message, "; "+ErrorSourcePosition1.New(nil, position).Error())
+	}
+	return diagnostic
+}
+
+// diagnosticCodes stable codes of errors, codes MUST NOT be changed once
+// released, new errors should be added with new codes
+var diagnosticCodes = map[errutil.ErrorFactory]string{
+	ErrorLoadProjectFile1:                 "project-file-invalid",
+	ErrorInvalidProjectFile:               "project-file-invalid",
+	ErrorProjectConflict3:                 "project-conflict",
+	ErrorLoadExternalLibrary1:             "library-load-failed",
+	ErrorUnsupportedValueType1:            "value-type-unsupported",
+	ErrorUnsupportedIncludeType1:          "include-type-unsupported",
+	ErrorInvalidTargetLocation1:           "target-location-invalid",
+	ErrorUnexpectedRAMLVersion2:           "raml-version-unexpected",
+	ErrorUnknownFragmentKind1:             "fragment-kind-unknown",
+	ErrorUnexpectedFragmentKind2:          "fragment-kind-unexpected",
+	ErrorExtendsUndefined1:                "extends-undefined",
+	ErrorExtendsCyclic1:                   "extends-cyclic",
+	ErrorOverlayInvalidNodes1:             "overlay-invalid-nodes",
+	ErrorIncludeFile1:                     "include-failed",
+	ErrorIncludeCyclic1:                   "include-cyclic",
+	ErrorIncludeDepthExceeded1:            "include-depth-exceeded",
+	ErrorIncludeBytesExceeded1:            "include-bytes-exceeded",
+	ErrorFileOutsideSandbox1:              "file-outside-sandbox",
+	ErrorYAMLAliasExpansionExceeded1:      "yaml-alias-expansion-exceeded",
+	ErrorEmptyRootDocumentMediaType:       "media-type-undefined",
+	ErrorAnnotationTypeUndefined1:         "annotation-type-undefined",
+	ErrorInvalidAnnotationTargetLocation2: "annotation-target-invalid",
+	ErrorTypeUndefined1:                   "type-undefined",
+	ErrorTypeCyclicInheritance1:           "type-inheritance-cyclic",
+	ErrorTypeConvertFailed2:               "type-convert-failed",
+	ErrorTypo2:                            "typo",
+	ErrorArrayElementTypeMismatch3:        "array-element-type-mismatch",
+	ErrorPropertyTypeMismatch1:            "property-type-mismatch",
+	ErrorPropertyTypeMismatch2:            "property-type-mismatch",
+	ErrorPropertyTypeMismatch3:            "property-type-mismatch",
+	ErrorPropertyUndefined2:               "property-undefined",
+	ErrorPropertyOverrideIncompatible3:    "property-override-incompatible",
+	ErrorRequiredProperty2:                "property-required",
+	ErrorValueCheckFailed2:                "value-check-failed",
+	ErrorUnusedTrait1:                     "trait-unused",
+	ErrorUnusedAnnotation1:                "annotation-unused",
+	ErrorUnusedType1:                      "type-unused",
+	ErrorUnusedLibrary1:                   "library-unused",
+	ErrorTraitNotFound1:                   "trait-undefined",
+	ErrorResourceTypeNotFound1:            "resource-type-undefined",
+	ErrorSecuritySchemeNotFound1:          "security-scheme-undefined",
+	ErrorUseNotFound1:                     "use-undefined",
+	ErrorYAMLParseFailed:                  "yaml-invalid",
+	ErrorYAMLParseFailed1:                 "yaml-invalid",
+}
+
+// DiagnosticCode return the stable code of err, "unknown" if not defined
+func DiagnosticCode(err error) string {
+	if code, ok := diagnosticCodes[errutil.FactoryOf(err)]; ok {
+		return code
+	}
+	return "unknown"
+}
+
+// NewDiagnosticCollector return DiagnosticCollector for parser config
+// DiagnosticCollector
+func NewDiagnosticCollector() *DiagnosticCollector {
+	return &DiagnosticCollector{}
+}
+
+// DiagnosticCollector collect all diagnostics of parsing instead of failing
+// on the first error, it is safe for concurrent use
+type DiagnosticCollector struct {
+	lock        sync.Mutex
+	diagnostics []Diagnostic
+}
+
+// Add diagnostic, duplicated diagnostics are ignored
+func (t *DiagnosticCollector) Add(diagnostic Diagnostic) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for _, exist := range t.diagnostics {
+		if exist.Severity == diagnostic.Severity &&
+			exist.Code == diagnostic.Code &&
+			exist.Message == diagnostic.Message &&
+			exist.Position == diagnostic.Position {
+			return
+		}
+	}
+	t.diagnostics = append(t.diagnostics, diagnostic)
+}
+
+// Diagnostics return collected diagnostics sorted by position
+func (t *DiagnosticCollector) Diagnostics() []Diagnostic {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	diagnostics := append([]Diagnostic{}, t.diagnostics...)
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		a, b := diagnostics[i].Position, diagnostics[j].Position
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return diagnostics
+}
+
+// HasErrors return true if any diagnostic of SeverityError collected
+func (t *DiagnosticCollector) HasErrors() bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for _, diagnostic := range t.diagnostics {
+		if diagnostic.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Reset remove all collected diagnostics
+func (t *DiagnosticCollector) Reset() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.diagnostics = nil
+}
+
+// diagnosticCollectorOf return the DiagnosticCollector of parser config,
+// nil if not collecting diagnostics
+func diagnosticCollectorOf(parser Parser) *DiagnosticCollector {
+	value, err := parser.Get(parserConfig.DiagnosticCollector)
+	if err != nil {
+		return nil
+	}
+	collector, _ := value.(*DiagnosticCollector)
+	return collector
+}
+
+// reportWarning add err as warning diagnostic if collecting diagnostics,
+// otherwise return err to fail parsing
+func reportWarning(conf PostProcessConfig, err error) error {
+	collector := diagnosticCollectorOf(conf.Parser())
+	if collector == nil {
+		return err
+	}
+	collector.Add(newDiagnostic(SeverityWarning, err))
+	return nil
+}
+
+// finishParse make the returned error of parsing support errors.As, and
+// add it as error diagnostic if collecting diagnostics, duplicated
+// diagnostics reported by postProcess are ignored by collector
+func (t parserImpl) finishParse(err *error) {
+	if *err == nil {
+		return
+	}
+	*err = exportError(*err)
+	if t.diagnosticCollector != nil {
+		t.diagnosticCollector.Add(newDiagnostic(SeverityError, *err))
+	}
+}
@@ -0,0 +1,226 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/tsaikd/KDGoLib/errutil"
+)
+
+// typedError base of exported error structs, the embedded ErrorObject is
+// created by the error factory, so factory matching still works
+type typedError struct {
+	errutil.ErrorObject
+}
+
+// Unwrap return the parent error, used by errors.Is and errors.As
+func (t typedError) Unwrap() error {
+	return unwrapParent(t.ErrorObject)
+}
+
+// Position return the source position of the node causing error
+func (t typedError) Position() (position Position, ok bool) {
+	return ErrorPosition(t.ErrorObject)
+}
+
+// TypeMismatchError value type mismatch the expected type, created by
+// ErrorPropertyTypeMismatch1, ErrorPropertyTypeMismatch2,
+// ErrorPropertyTypeMismatch3, ErrorArrayElementTypeMismatch3 and
+// ErrorPropertyOverrideIncompatible3
+type TypeMismatchError struct {
+	typedError
+	// property names and array indexes from the checked value to the
+	// mismatched value, empty if the checked value is mismatched
+	Path     []string
+	Expected string
+	Actual   string
+}
+
+// Pointer return JSON pointer of Path, e.g. "/orders/3/items/0/price"
+func (t TypeMismatchError) Pointer() string {
+	return valuePointer(t.Path)
+}
+
+func newTypeMismatchError(
+	factory errutil.ErrorFactory,
+	parent error,
+	path []string,
+	expected string,
+	actual string,
+	params ...interface{},
+) *TypeMismatchError {
+	return &TypeMismatchError{
+		typedError: typedError{factory.New(parent, params...)},
+		Path:       path,
+		Expected:   expected,
+		Actual:     actual,
+	}
+}
+
+// RequiredPropertyError required property not found in value, created by
+// ErrorRequiredProperty2
+type RequiredPropertyError struct {
+	typedError
+	// property names and array indexes from the checked value to the
+	// required property
+	Path     []string
+	Property string
+	// type declaring the property
+	Type string
+}
+
+func newRequiredPropertyError(path []string, property string, typ string) *RequiredPropertyError {
+	return &RequiredPropertyError{
+		typedError: typedError{ErrorRequiredProperty2.New(valuePointerError(path), property, typ)},
+		Path:       path,
+		Property:   property,
+		Type:       typ,
+	}
+}
+
+// Pointer return JSON pointer of Path, e.g. "/orders/3/items/0/name"
+func (t RequiredPropertyError) Pointer() string {
+	return valuePointer(t.Path)
+}
+
+// ValueCheckError all failures of checking value, returned by
+// CheckValueAPIType with CheckValueOptionAllFailures if more than one
+// failure, created by ErrorValueCheckFailed2
+type ValueCheckError struct {
+	errutil.ErrorObject
+	Failures []error
+}
+
+func newValueCheckError(failures []error) *ValueCheckError {
+	texts := []string{}
+	for i, failure := range failures {
+		failures[i] = exportError(failure)
+		texts = append(texts, failure.Error())
+	}
+	return &ValueCheckError{
+		ErrorObject: ErrorValueCheckFailed2.New(nil, len(failures), strings.Join(texts, "; ")),
+		Failures:    failures,
+	}
+}
+
+// Unwrap return all failures, used by errors.Is and errors.As
+func (t ValueCheckError) Unwrap() []error {
+	return t.Failures
+}
+
+// UndefinedKind kind of UndefinedError
+type UndefinedKind string
+
+// List all valid UndefinedKind
+const (
+	UndefinedAnnotationType UndefinedKind = "annotation type"
+	UndefinedProperty       UndefinedKind = "property"
+	UndefinedResourceType   UndefinedKind = "resource type"
+	UndefinedSecurityScheme UndefinedKind = "security scheme"
+	UndefinedTrait          UndefinedKind = "trait"
+	UndefinedType           UndefinedKind = "type"
+	UndefinedUse            UndefinedKind = "use"
+)
+
+// UndefinedError referenced declaration not found, created by
+// ErrorAnnotationTypeUndefined1, ErrorPropertyUndefined2,
+// ErrorResourceTypeNotFound1, ErrorSecuritySchemeNotFound1,
+// ErrorTraitNotFound1, ErrorTypeUndefined1 and ErrorUseNotFound1
+type UndefinedError struct {
+	typedError
+	Kind UndefinedKind
+	// referenced name, MAY be prefixed by library names
+	Name string
+	// type declaring the property, only for UndefinedProperty
+	Type string
+}
+
+func newUndefinedError(factory errutil.ErrorFactory, kind UndefinedKind, name string, params ...interface{}) *UndefinedError {
+	if len(params) < 1 {
+		params = []interface{}{name}
+	}
+	return &UndefinedError{
+		typedError: typedError{factory.New(nil, params...)},
+		Kind:       kind,
+		Name:       name,
+	}
+}
+
+// UnknownFacetError unknown facets, which might be typo, created by ErrorTypo2
+type UnknownFacetError struct {
+	typedError
+	// the node declaring facets, e.g. "Method"
+	Node   string
+	Facets []string
+	// the closest valid facet of unknown facets, e.g. "description" for
+	// "descripton", facets without close valid facet are not included
+	Suggestions map[string]string
+}
+
+func newUnknownFacetError(node string, facets []string, keys typoKeys) *UnknownFacetError {
+	suggestions := map[string]string{}
+	var parent error
+	for i := len(facets) - 1; i >= 0; i-- {
+		if suggestion := keys.suggest(facets[i]); suggestion != "" {
+			suggestions[facets[i]] = suggestion
+			parent = ErrorTypoSuggestion2.New(parent, suggestion, facets[i])
+		}
+	}
+	return &UnknownFacetError{
+		typedError:  typedError{ErrorTypo2.New(parent, node, facets)},
+		Node:        node,
+		Facets:      facets,
+		Suggestions: suggestions,
+	}
+}
+
+// unwrapError make errutil.ErrorObject support errors.Unwrap by its parent
+type unwrapError struct {
+	errutil.ErrorObject
+}
+
+func (t unwrapError) Unwrap() error {
+	return unwrapParent(t.ErrorObject)
+}
+
+type unwrapper interface {
+	Unwrap() error
+}
+
+type multiUnwrapper interface {
+	Unwrap() []error
+}
+
+// unwrapParent return the parent of errobj which supports errors.Unwrap
+func unwrapParent(errobj errutil.ErrorObject) error {
+	parent := errobj.Parent()
+	if parent == nil {
+		return nil
+	}
+	return exportError(parent)
+}
+
+// appendErrorParent append parent to the error chain of err, e.g. context of
+// the failure, so err is still the returned error for factory matching
+func appendErrorParent(err error, parent error) error {
+	errobj := errutil.NewErrors(err)
+	if errutil.AddParent(errobj, errutil.NewErrors(parent)) != nil {
+		return err
+	}
+	return errobj
+}
+
+// exportError return err which supports errors.Is and errors.As through
+// the errutil parents, should be called before returning error to users
+func exportError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch err.(type) {
+	case unwrapper, multiUnwrapper:
+		return err
+	}
+	if errobj, ok := err.(errutil.ErrorObject); ok {
+		return unwrapError{errobj}
+	}
+	return err
+}
@@ -9,6 +9,11 @@ import (
 // errors
 var (
 	ErrorCacheNotFound                    = errutil.NewFactory("cache not found")
+	ErrorCacheLocked1                     = errutil.NewFactory("cache is locked by %q")
+	ErrorLoadProjectFile1                 = errutil.NewFactory("load project file failed: %q")
+	ErrorInvalidProjectFile               = errutil.NewFactory("project file should be a map of RAML nodes")
+	ErrorProjectConflict3                 = errutil.NewFactory("project node %q is declared in both %q and %q")
+	ErrorSourcePosition1                  = errutil.NewFactory("at %s")
 	ErrorLoadExternalLibrary1             = errutil.NewFactory("load external library failed: %q")
 	ErrorUnsupportedParserConfig1         = errutil.NewFactory("unsupported parser config: %q")
 	ErrorUnsupportedValueType1            = errutil.NewFactory("unsupported value type: %T")
@@ -17,21 +22,45 @@ var (
 	ErrorInvalidParserConfigValueType3    = errutil.NewFactory(`value type of parser config %q should be "%T" but got "%T"`)
 	ErrorInvalidTargetLocation1           = errutil.NewFactory("invalid TargetLocation string %q")
 	ErrorUnexpectedRAMLVersion2           = errutil.NewFactory("RAML version should be %q but got %q")
+	ErrorUnknownFragmentKind1             = errutil.NewFactory("unknown RAML fragment kind %q")
+	ErrorUnexpectedFragmentKind2          = errutil.NewFactory("RAML fragment kind should be %q but got %q")
+	ErrorExtendsUndefined1                = errutil.NewFactory("%s should declare the master RAML file by extends")
+	ErrorExtendsCyclic1                   = errutil.NewFactory("extends is cyclic on %q")
+	ErrorOverlayInvalidNodes1             = errutil.NewFactory("overlay can only add or override annotations, examples and documentation nodes, but changed: %s")
+	ErrorIncludeFile1                     = errutil.NewFactory("include file failed: %q")
+	ErrorIncludeCyclic1                   = errutil.NewFactory("include is cyclic: %s")
+	ErrorIncludeDepthExceeded1            = errutil.NewFactory("include depth exceeds limit %d")
+	ErrorIncludeBytesExceeded1            = errutil.NewFactory("total bytes of included files exceed limit %d")
+	ErrorFileOutsideSandbox1              = errutil.NewFactory("file %q is outside of sandbox directory")
+	ErrorYAMLAliasExpansionExceeded1      = errutil.NewFactory("YAML nodes exceed limit %d after expanding aliases")
 	ErrorEmptyRootDocumentMediaType       = errutil.NewFactory("body without MIME-type and root document do not provide default MediaType")
 	ErrorAnnotationTypeUndefined1         = errutil.NewFactory("Annotation type %q can not find in RAML")
 	ErrorInvalidAnnotationTargetLocation2 = errutil.NewFactory("Annotation %q is invalid for TargetLocation %q")
+	ErrorAnnotationValue1                 = errutil.NewFactory("in value of annotation %q")
+	ErrorAnnotationDecode1                = errutil.NewFactory("decode value of annotation %q failed")
 	ErrorTypeUndefined1                   = errutil.NewFactory("Type %q can not find in RAML")
+	ErrorTypeCyclicInheritance1           = errutil.NewFactory("Type inheritance is cyclic: %s")
 	ErrorTypeConvertFailed2               = errutil.NewFactory("can not convert type from %q to %q")
 	ErrorTypo2                            = errutil.NewFactory("detect typo error on %q: %v")
+	ErrorTypoSuggestion2                  = errutil.NewFactory("did you mean %q instead of %q?")
 	ErrorArrayElementTypeMismatch3        = errutil.NewFactory("array element %d type mismatch, expected %q but got %q")
 	ErrorPropertyTypeMismatch1            = errutil.NewFactory("Property %q type mismatch")
 	ErrorPropertyTypeMismatch2            = errutil.NewFactory("Property type mismatch, expected %q but got %q")
 	ErrorPropertyTypeMismatch3            = errutil.NewFactory("Property %q type mismatch, expected %q but got %q")
 	ErrorPropertyUndefined2               = errutil.NewFactory("Property %q can not find in APIType %q")
+	ErrorPropertyOverrideIncompatible3    = errutil.NewFactory("Property %q of type %q is incompatible to override parent type %q")
 	ErrorRequiredProperty2                = errutil.NewFactory("Property %q is required but not found in %q")
+	ErrorValuePointer1                    = errutil.NewFactory("at value %s")
+	ErrorExampleValue1                    = errutil.NewFactory("in example %q")
+	ErrorGenerateValuePattern1            = errutil.NewFactory("generate value of pattern %q failed")
+	ErrorValueCheckFailed2                = errutil.NewFactory("%d failures of checking value: %s")
 	ErrorUnusedTrait1                     = errutil.NewFactory("Trait %q is unused")
 	ErrorUnusedAnnotation1                = errutil.NewFactory("Annotation %q is unused")
+	ErrorUnusedType1                      = errutil.NewFactory("Type %q is unused")
+	ErrorUnusedLibrary1                   = errutil.NewFactory("Library %q is unused")
 	ErrorTraitNotFound1                   = errutil.NewFactory("trait %q not found")
+	ErrorResourceTypeNotFound1            = errutil.NewFactory("resource type %q not found")
+	ErrorSecuritySchemeNotFound1          = errutil.NewFactory("security scheme %q not found")
 	ErrorUseNotFound1                     = errutil.NewFactory("use %q not found")
 	ErrorYAMLParseFailed                  = errutil.NewFactory("YAML parse failed")
 	ErrorYAMLParseFailed1                 = errutil.NewFactory("%v\nYAML parse failed")
@@ -98,3 +98,82 @@ func ExampleParser_cache() {
 	// Mobile Order API
 	// Mobile Order API
 }
+
+func ExampleDecodeAnnotation() {
+	ramlParser := parser.NewParser()
+	data := []byte(strings.TrimSpace(`
+#%RAML 1.0
+annotationTypes:
+    owner: string
+    rateLimit:
+        properties:
+            perMinute: integer
+/user:
+    get:
+        (owner): team-x
+        (rateLimit):
+            perMinute: 60
+	`))
+
+	rootdoc, err := ramlParser.ParseData(data, ".")
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	method := rootdoc.Resources["/user"].Methods["get"]
+
+	var owner string
+	if _, err = parser.DecodeAnnotation(method, "owner", &owner); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println("owner:", owner)
+
+	rateLimit := struct {
+		PerMinute int `json:"perMinute"`
+	}{}
+	if _, err = parser.DecodeAnnotation(method, "rateLimit", &rateLimit); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println("rate limit per minute:", rateLimit.PerMinute)
+
+	// Output:
+	// owner: team-x
+	// rate limit per minute: 60
+}
+
+func ExampleGenerateValue() {
+	ramlParser := parser.NewParser()
+	data := []byte(strings.TrimSpace(`
+#%RAML 1.0
+types:
+    Order:
+        properties:
+            code:
+                type: string
+                pattern: ^ORD-[0-9]{4}$
+            quantity:
+                type: integer
+                minimum: 1
+                maximum: 9
+            status:
+                enum: [open, closed]
+	`))
+
+	rootdoc, err := ramlParser.ParseData(data, ".")
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	value, err := parser.GenerateValue(*rootdoc.Types["Order"], rootdoc.Library, parser.GenerateValueOptionSeed(1))
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println("code:", value.Map["code"].String)
+	fmt.Println("quantity:", value.Map["quantity"].Integer)
+	fmt.Println("status:", value.Map["status"].String)
+
+	// Output:
+	// code: ORD-7791
+	// quantity: 9
+	// status: closed
+}
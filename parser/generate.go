@@ -0,0 +1,412 @@
+package parser
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tsaikd/go-raml-parser/parser/parserConfig"
+)
+
+// GenerateValueOption for changing GenerateValue behavior
+type GenerateValueOption interface{}
+
+// GenerateValueOptionSeed seed of the random source, the same value is
+// generated for the same seed
+// default: 0
+type GenerateValueOptionSeed int64
+
+// GenerateValueOptionIgnoreExamples synthesise values by facets even if the
+// types declare examples
+// default: false
+type GenerateValueOptionIgnoreExamples bool
+
+// GenerateValue generate a value of apiType honouring facets of the type,
+// e.g. enum, pattern, length, numeric range, date format and array limits,
+// declared types are resolved in library, declared examples are reused
+// unless GenerateValueOptionIgnoreExamples
+func GenerateValue(apiType APIType, library Library, options ...GenerateValueOption) (value Value, err error) {
+	value, err = generateValueWithOptions(apiType, library, options...)
+	return value, exportError(err)
+}
+
+// generateValueWithOptions return the internal error of GenerateValue
+func generateValueWithOptions(apiType APIType, library Library, options ...GenerateValueOption) (value Value, err error) {
+	seed := GenerateValueOptionSeed(0)
+	ignoreExamples := GenerateValueOptionIgnoreExamples(false)
+
+	for _, option := range options {
+		switch optval := option.(type) {
+		case GenerateValueOptionSeed:
+			seed = optval
+		case GenerateValueOptionIgnoreExamples:
+			ignoreExamples = optval
+		}
+	}
+
+	generator := valueGenerator{
+		random:         rand.New(rand.NewSource(int64(seed))),
+		ignoreExamples: bool(ignoreExamples),
+		expanding:      map[*APIType]bool{},
+	}
+	return generator.generate(library, apiType)
+}
+
+// generateValueOptionsOf return GenerateValueOptions of parser config,
+// generate is false if not configured
+func generateValueOptionsOf(conf PostProcessConfig) (options []GenerateValueOption, generate bool) {
+	value, err := conf.Parser().Get(parserConfig.GenerateValueOptions)
+	if err != nil {
+		return nil, false
+	}
+	options, ok := value.([]GenerateValueOption)
+	return options, ok && options != nil
+}
+
+const (
+	// generated strings are about preferStringLength long if not limited
+	preferStringLength = 4
+	// generated arrays have about preferArrayLength items if not limited
+	preferArrayLength = 1
+	// max extra length of generated strings
+	generateStringSpan = 8
+	// max extra length of generated arrays
+	generateArraySpan = 2
+	// max extra repeat of unlimited repetition in pattern
+	generateRepeatSpan = 4
+	// max range of generated numbers
+	generateNumberSpan = 100
+	// max attempts of generating unique items or pattern in length
+	generateAttempts = 10
+)
+
+// dateTimeRFC2616 layout of datetime with rfc2616 format
+const dateTimeRFC2616 = "Mon, 02 Jan 2006 15:04:05 GMT"
+
+// numberFormatRange value range of integer formats
+var numberFormatRange = map[string][2]float64{
+	"int8":  {math.MinInt8, math.MaxInt8},
+	"int16": {math.MinInt16, math.MaxInt16},
+	"int32": {math.MinInt32, math.MaxInt32},
+	"int64": {math.MinInt64, math.MaxInt64},
+	"int":   {math.MinInt64, math.MaxInt64},
+	"long":  {math.MinInt64, math.MaxInt64},
+}
+
+// generateChars characters of generated strings and any character of pattern
+const generateChars = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// valueGenerator generate values by the random source,
+// expanding is the declared types in generating, used to stop recursive types
+type valueGenerator struct {
+	random         *rand.Rand
+	ignoreExamples bool
+	expanding      map[*APIType]bool
+}
+
+func (t valueGenerator) generate(library Library, apiType APIType) (value Value, err error) {
+	if !t.ignoreExamples {
+		if value = declaredExampleValue(apiType); !value.IsEmpty() {
+			return
+		}
+	}
+
+	if apiType.IsArray {
+		return t.generateArray(library, apiType)
+	}
+
+	if len(apiType.Enum) > 0 {
+		return apiType.Enum[t.random.Intn(len(apiType.Enum))], nil
+	}
+
+	if members := splitUnionType(apiType.Type); len(members) > 1 {
+		var member APIType
+		if member, err = unionMemberAPIType(library, members[t.random.Intn(len(members))]); err != nil {
+			return
+		}
+		return t.generate(library, member)
+	}
+
+	if !isInlineAPIType(apiType) {
+		if typ, typeLibrary, reusable := getExampleReusableType(library, apiType); typ != nil {
+			if t.expanding[typ] {
+				// stop generating recursive type
+				return Value{}, nil
+			}
+			t.expanding[typ] = true
+			defer delete(t.expanding, typ)
+			if reusable && !t.ignoreExamples {
+				if value = declaredExampleValue(*typ); !value.IsEmpty() {
+					return
+				}
+			}
+			// facets of declared type are merged into apiType
+			library = typeLibrary
+		}
+	}
+
+	switch apiType.NativeType {
+	case TypeNull:
+		return NewValue(nil)
+	case TypeBoolean:
+		return NewValue(t.random.Intn(2) == 1)
+	case TypeInteger:
+		return NewValue(int64(t.generateNumber(apiType.Number, true)))
+	case TypeNumber:
+		return NewValue(t.generateNumber(apiType.Number, false))
+	case TypeString:
+		return t.generateString(apiType.String)
+	case TypeDateOnly, TypeTimeOnly, TypeDateTimeOnly, TypeDateTime:
+		return NewValue(t.generateDate(apiType.NativeType, apiType.Format))
+	case TypeFile:
+		return Value{}, nil
+	}
+
+	if isInlineAPIType(apiType) {
+		// not support generate value of inline APIType
+		return Value{}, nil
+	}
+
+	valmap := map[string]interface{}{}
+	for _, property := range apiType.resolved().Properties.Slice() {
+		var propertyValue Value
+		if propertyValue, err = t.generate(library, property.APIType); err != nil {
+			return
+		}
+		if propertyValue.IsEmpty() && !property.Required {
+			continue
+		}
+		valmap[property.Name] = propertyValue
+	}
+	return NewValue(valmap)
+}
+
+// declaredExampleValue return value of declared example of apiType,
+// examples are searched in the order of names, generated examples are ignored
+func declaredExampleValue(apiType APIType) Value {
+	if !apiType.Example.Generated && !apiType.Example.Value.IsEmpty() {
+		return apiType.Example.Value
+	}
+	names := []string{}
+	for name, example := range apiType.Examples {
+		if example != nil && !example.Generated && !example.Value.IsEmpty() {
+			names = append(names, name)
+		}
+	}
+	if len(names) < 1 {
+		return Value{}
+	}
+	sort.Strings(names)
+	return apiType.Examples[names[0]].Value
+}
+
+func (t valueGenerator) generateArray(library Library, apiType APIType) (value Value, err error) {
+	elemType := apiType
+	elemType.IsArray = false
+	// examples of apiType are arrays instead of items
+	elemType.Example, elemType.Examples = Example{}, nil
+
+	length := t.generateLength(apiType.MinItems, apiType.MaxItems, preferArrayLength, generateArraySpan)
+	result := []interface{}{}
+	for attempt := int64(0); int64(len(result)) < length && attempt < length*generateAttempts; attempt++ {
+		var elem Value
+		if elem, err = t.generate(library, elemType); err != nil {
+			return
+		}
+		if elem.IsEmpty() {
+			if len(result) < 1 {
+				// end of recursive type
+				return Value{}, nil
+			}
+			break
+		}
+		if apiType.UniqueItems && containsValue(result, elem) {
+			continue
+		}
+		result = append(result, elem)
+	}
+	return NewValue(result)
+}
+
+// containsValue return true if values contains value
+func containsValue(values []interface{}, value Value) bool {
+	for _, elem := range values {
+		if reflect.DeepEqual(elem, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// generateLength return random length in [min, max], lengths in
+// [prefer, prefer+span] are generated if not limited
+func (t valueGenerator) generateLength(min int64, max int64, prefer int64, span int64) int64 {
+	if max < min {
+		max = min
+	}
+	low := min
+	if low < prefer {
+		low = prefer
+	}
+	if low > max {
+		low = max
+	}
+	high := low + span
+	if high > max {
+		high = max
+	}
+	return low + t.random.Int63n(high-low+1)
+}
+
+// generateNumber return random number in the range of facets, integer is
+// true if the number MUST be an integer
+func (t valueGenerator) generateNumber(facets Number, integer bool) float64 {
+	low, high := 0.0, float64(generateNumberSpan)
+	switch {
+	case facets.Minimum != nil && facets.Maximum != nil:
+		low, high = *facets.Minimum, *facets.Maximum
+	case facets.Minimum != nil:
+		low, high = *facets.Minimum, *facets.Minimum+generateNumberSpan
+	case facets.Maximum != nil:
+		low, high = *facets.Maximum-generateNumberSpan, *facets.Maximum
+	}
+	if limit, ok := numberFormatRange[facets.Format]; ok {
+		low, high = math.Max(low, limit[0]), math.Min(high, limit[1])
+		integer = true
+	}
+	if integer {
+		low, high = math.Ceil(low), math.Floor(high)
+	}
+	if high < low {
+		return low
+	}
+	if high-low > generateNumberSpan {
+		high = low + generateNumberSpan
+	}
+
+	if facets.MultipleOf != nil && *facets.MultipleOf > 0 {
+		step := *facets.MultipleOf
+		first, last := math.Ceil(low/step), math.Floor(high/step)
+		if last < first {
+			return low
+		}
+		return (first + float64(t.random.Int63n(int64(last-first)+1))) * step
+	}
+	if integer {
+		return low + float64(t.random.Int63n(int64(high-low)+1))
+	}
+	number := math.Round((low+t.random.Float64()*(high-low))*100) / 100
+	return math.Min(math.Max(number, low), high)
+}
+
+func (t valueGenerator) generateString(facets String) (value Value, err error) {
+	if facets.Pattern == "" {
+		length := t.generateLength(facets.MinLength, facets.MaxLength, preferStringLength, generateStringSpan)
+		buffer := make([]byte, length)
+		for i := range buffer {
+			buffer[i] = generateChars[t.random.Intn(len(generateChars))]
+		}
+		return NewValue(string(buffer))
+	}
+
+	regex, err := syntax.Parse(facets.Pattern, syntax.Perl)
+	if err != nil {
+		return Value{}, ErrorGenerateValuePattern1.New(err, facets.Pattern)
+	}
+	// the pattern has priority if no generated string is in length
+	result := ""
+	for attempt := 0; attempt < generateAttempts; attempt++ {
+		builder := &strings.Builder{}
+		t.generatePattern(builder, regex)
+		result = builder.String()
+		length := int64(len([]rune(result)))
+		if facets.MinLength <= length && length <= facets.MaxLength {
+			break
+		}
+	}
+	return NewValue(result)
+}
+
+// generatePattern write a string matching regex to builder
+func (t valueGenerator) generatePattern(builder *strings.Builder, regex *syntax.Regexp) {
+	switch regex.Op {
+	case syntax.OpLiteral:
+		builder.WriteString(string(regex.Rune))
+	case syntax.OpCharClass:
+		builder.WriteRune(t.generateRune(regex.Rune))
+	case syntax.OpAnyCharNotNL, syntax.OpAnyChar:
+		builder.WriteByte(generateChars[t.random.Intn(len(generateChars))])
+	case syntax.OpCapture:
+		t.generatePattern(builder, regex.Sub[0])
+	case syntax.OpStar:
+		t.generateRepeat(builder, regex.Sub[0], 0, -1)
+	case syntax.OpPlus:
+		t.generateRepeat(builder, regex.Sub[0], 1, -1)
+	case syntax.OpQuest:
+		t.generateRepeat(builder, regex.Sub[0], 0, 1)
+	case syntax.OpRepeat:
+		t.generateRepeat(builder, regex.Sub[0], regex.Min, regex.Max)
+	case syntax.OpConcat:
+		for _, sub := range regex.Sub {
+			t.generatePattern(builder, sub)
+		}
+	case syntax.OpAlternate:
+		t.generatePattern(builder, regex.Sub[t.random.Intn(len(regex.Sub))])
+	}
+	// empty string matches other operators, e.g. ^, $ and \b
+}
+
+// generateRepeat write sub repeated in [min, max] times to builder,
+// max < 0 means unlimited
+func (t valueGenerator) generateRepeat(builder *strings.Builder, sub *syntax.Regexp, min int, max int) {
+	if max < 0 {
+		max = min + generateRepeatSpan
+	}
+	count := min + t.random.Intn(max-min+1)
+	for i := 0; i < count; i++ {
+		t.generatePattern(builder, sub)
+	}
+}
+
+// generateRune return random rune in ranges of character class, printable
+// ASCII characters are preferred
+func (t valueGenerator) generateRune(ranges []rune) rune {
+	printable := []rune{}
+	for i := 0; i+1 < len(ranges); i += 2 {
+		for r := ranges[i]; r <= ranges[i+1] && r <= '~'; r++ {
+			if r >= ' ' {
+				printable = append(printable, r)
+			}
+		}
+	}
+	if len(printable) > 0 {
+		return printable[t.random.Intn(len(printable))]
+	}
+	if len(ranges) < 2 {
+		return ' '
+	}
+	i := t.random.Intn(len(ranges)/2) * 2
+	return ranges[i] + rune(t.random.Int63n(int64(ranges[i+1]-ranges[i])+1))
+}
+
+// generateDate return random date in the format of date type
+func (t valueGenerator) generateDate(typ string, format string) string {
+	date := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC).
+		Add(time.Duration(t.random.Int63n(20*365*24*60*60)) * time.Second)
+	switch typ {
+	case TypeDateOnly:
+		return date.Format("2006-01-02")
+	case TypeTimeOnly:
+		return date.Format("15:04:05")
+	case TypeDateTimeOnly:
+		return date.Format("2006-01-02T15:04:05")
+	}
+	if format == "rfc2616" {
+		return date.Format(dateTimeRFC2616)
+	}
+	return date.Format(time.RFC3339)
+}
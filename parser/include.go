@@ -0,0 +1,299 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// resolveIncludes return data with !include nodes replaced by the content of
+// included files, references are resolved from the document at location,
+// included binary files keep the !include tag with the canonical location,
+// data is returned directly if no !include found
+func resolveIncludes(loader *fileLoader, data []byte, location string) (result []byte, err error) {
+	if !bytes.Contains(data, []byte("!include")) {
+		return data, nil
+	}
+
+	tree := &yamlNode{}
+	if err = loader.unmarshalYAML(data, tree); err != nil {
+		if ErrorYAMLAliasExpansionExceeded1.Match(err) {
+			return
+		}
+		// report by the caller with error trace
+		return data, nil
+	}
+
+	count := 0
+	if err = resolveIncludeNode(loader, tree, location, "", includeContextValue, 0, 0, nil, &count); err != nil {
+		return
+	}
+	if count < 1 {
+		return data, nil
+	}
+
+	// keep RAML header for checking fragment kind
+	header := ""
+	if bytes.HasPrefix(data, []byte("#%RAML")) {
+		header = strings.SplitN(string(data), "\n", 2)[0]
+	}
+	return append([]byte(header+"\n"), tree.marshalFlow()...), nil
+}
+
+// resolveIncludeNode replace !include nodes in node, pointer is the JSON
+// pointer of node in the document at location, which is recorded as the
+// position site of included content, context is the kind of node value,
+// kind is the fragment kind of node if included, entryKind is the fragment
+// kind of entries if node is declarations, e.g. the value of types node,
+// stack is the canonical locations of including documents used to detect
+// cycle, count is the number of !include found
+func resolveIncludeNode(
+	loader *fileLoader,
+	node *yamlNode,
+	location string,
+	pointer string,
+	context includeContext,
+	kind FragmentKind,
+	entryKind FragmentKind,
+	stack []string,
+	count *int,
+) (err error) {
+	switch value := node.Value.(type) {
+	case yamlMap:
+		for _, item := range value {
+			key, _ := item.Key.(string)
+			childPointer := pointer + "/" + escapeJSONPointer(fmt.Sprint(item.Key))
+			childEntryKind := FragmentKind(0)
+			if pointer == "" && len(stack) < 1 {
+				// root nodes of the document being resolved
+				childEntryKind = declarationFragmentKinds[key]
+			}
+			if err = resolveIncludeNode(loader, item.Value, location, childPointer, includeContextOf(key, context), entryKind, childEntryKind, stack, count); err != nil {
+				return
+			}
+		}
+		return
+	case []*yamlNode:
+		for i, elem := range value {
+			if err = resolveIncludeNode(loader, elem, location, pointer+"/"+strconv.Itoa(i), context, entryKind, 0, stack, count); err != nil {
+				return
+			}
+		}
+		return
+	}
+
+	path, ok := node.Value.(string)
+	if node.Tag != "!include" || !ok {
+		return
+	}
+	*count++
+
+	if err = loader.checkIncludeDepth(len(stack) + 1); err != nil {
+		return
+	}
+
+	fileData, filePath, err := loader.includeFile(location, path)
+	if err != nil {
+		if filePath == "" {
+			filePath = path
+		}
+		return ErrorIncludeFile1.New(err, filePath)
+	}
+	for i, including := range stack {
+		if including == filePath {
+			chain := append(append([]string{}, stack[i:]...), filePath)
+			return ErrorIncludeCyclic1.New(nil, strings.Join(chain, " -> "))
+		}
+	}
+
+	switch includeFileFormat(filePath, fileData, context) {
+	case includeFormatYAML:
+		switch {
+		case context == includeContextExample:
+			// examples MAY be declared by NamedExample fragment
+			if err = checkFragmentKind(fileData, false, FragmentKindNamedExample); err != nil {
+				return ErrorIncludeFile1.New(err, filePath)
+			}
+		case kind != 0:
+			if err = checkFragmentKind(fileData, false, kind); err != nil {
+				return ErrorIncludeFile1.New(err, filePath)
+			}
+		}
+		included := &yamlNode{}
+		if err = loader.unmarshalYAML(fileData, included); err != nil {
+			return ErrorIncludeFile1.New(err, filePath)
+		}
+		if err = resolveIncludeNode(loader, included, filePath, "", context, kind, entryKind, append(stack, filePath), count); err != nil {
+			return
+		}
+		*node = *included
+		loader.addPositionSite(location, pointer, filePath, "")
+		return
+	case includeFormatJSON:
+		if context == includeContextSchema {
+			// JSON schema
+			*node = yamlNode{Value: string(fileData)}
+			loader.addPositionSite(location, pointer, filePath, "")
+			return
+		}
+		var value interface{}
+		decoder := json.NewDecoder(bytes.NewReader(fileData))
+		decoder.UseNumber()
+		if err = decoder.Decode(&value); err != nil {
+			return ErrorIncludeFile1.New(err, filePath)
+		}
+		*node = *newYAMLNode(value)
+		loader.addPositionSite(location, pointer, filePath, "")
+		return
+	case includeFormatText:
+		// XML schema or other text files
+		*node = yamlNode{Value: string(fileData)}
+		loader.addPositionSite(location, pointer, filePath, "")
+		return
+	case includeFormatExampleText:
+		// parsed by the declared type of example, see fillExampleAPIType
+		*node = yamlNode{Tag: includeTextTag, Value: string(fileData)}
+		loader.addPositionSite(location, pointer, filePath, "")
+		return
+	}
+
+	// binary files are loaded by the node, e.g. example of file type
+	node.Value = filePath
+	return nil
+}
+
+// includeFormat format of included file
+type includeFormat int8
+
+// List all valid enum
+const (
+	includeFormatBinary includeFormat = iota
+	includeFormatText
+	includeFormatJSON
+	includeFormatYAML
+	// text file of example value which looks like JSON or YAML
+	includeFormatExampleText
+)
+
+// includeTextTag tag of included text file of example value which looks like
+// JSON or YAML, the text is parsed if the example is not a string
+const includeTextTag = "!includeText"
+
+// includeFileFormat return format of included file by extension, text files
+// of example values are RAML fragments if content starts with the header, or
+// are left to the declared type of example if content looks like JSON or YAML
+func includeFileFormat(filePath string, data []byte, context includeContext) includeFormat {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".raml", ".yaml", ".yml":
+		return includeFormatYAML
+	case ".json":
+		return includeFormatJSON
+	}
+
+	if !utf8.Valid(data) {
+		return includeFormatBinary
+	}
+	if context == includeContextExample {
+		content := bytes.TrimSpace(data)
+		switch {
+		case bytes.HasPrefix(content, []byte("#%RAML")):
+			return includeFormatYAML
+		case bytes.HasPrefix(content, []byte("{")), bytes.HasPrefix(content, []byte("[")):
+			if json.Valid(content) {
+				return includeFormatExampleText
+			}
+		case bytes.HasPrefix(content, []byte("---")):
+			return includeFormatExampleText
+		}
+	}
+	return includeFormatText
+}
+
+// includeContext kind of node value which MAY be included
+type includeContext int8
+
+// List all valid enum
+const (
+	// value of node, e.g. documentation content
+	includeContextValue includeContext = iota
+	// type or schema declaration, included JSON files are JSON schemas
+	includeContextSchema
+	// example value, included text files MAY be JSON or YAML
+	includeContextExample
+)
+
+// declarationFragmentKinds fragment kind of entries of root nodes, e.g. each
+// type of types node MAY be included from a DataType fragment
+var declarationFragmentKinds = map[string]FragmentKind{
+	"types":           FragmentKindDataType,
+	"schemas":         FragmentKindDataType,
+	"traits":          FragmentKindTrait,
+	"resourceTypes":   FragmentKindResourceType,
+	"annotationTypes": FragmentKindAnnotationTypeDeclaration,
+	"securitySchemes": FragmentKindSecurityScheme,
+	"documentation":   FragmentKindDocumentationItem,
+}
+
+// includeSchemaNodes nodes whose value is type declaration
+var includeSchemaNodes = map[string]bool{
+	"type":              true,
+	"types":             true,
+	"schema":            true,
+	"schemas":           true,
+	"items":             true,
+	"properties":        true,
+	"body":              true,
+	"headers":           true,
+	"queryParameters":   true,
+	"uriParameters":     true,
+	"baseUriParameters": true,
+}
+
+// includeContextOf return the context of the value of key, parent is the
+// context of the node containing key
+func includeContextOf(key string, parent includeContext) includeContext {
+	switch {
+	case parent == includeContextExample:
+		// keys in example are values instead of declaration
+		return includeContextExample
+	case key == "example", key == "examples":
+		return includeContextExample
+	case strings.HasPrefix(key, "("):
+		// values instead of declaration
+		return includeContextValue
+	case includeSchemaNodes[key]:
+		return includeContextSchema
+	default:
+		return parent
+	}
+}
+
+// newYAMLNode convert decoded JSON value to yamlNode
+func newYAMLNode(value interface{}) *yamlNode {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		keys := []string{}
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		result := yamlMap{}
+		for _, key := range keys {
+			result = append(result, yamlMapItem{Key: key, Value: newYAMLNode(value[key])})
+		}
+		return &yamlNode{Value: result}
+	case []interface{}:
+		result := []*yamlNode{}
+		for _, elem := range value {
+			result = append(result, newYAMLNode(elem))
+		}
+		return &yamlNode{Value: result}
+	default:
+		return &yamlNode{Value: value}
+	}
+}
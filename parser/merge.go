@@ -4,12 +4,13 @@ func mergeAPIType(dst *APIType, fromList ...APIType) {
 	for _, from := range fromList {
 		mergeTypeDeclaration(&dst.TypeDeclaration, from.TypeDeclaration)
 
-		if dst.ObjectType.IsEmpty() {
-			dst.ObjectType = from.ObjectType
-		}
+		mergeObjectType(&dst.ObjectType, from.ObjectType)
 		if dst.ScalarType.IsEmpty() {
 			dst.ScalarType = from.ScalarType
 		}
+		if dst.Number.IsEmpty() {
+			dst.Number = from.Number
+		}
 		if dst.String.IsEmpty() {
 			dst.String = from.String
 		}
@@ -24,6 +25,51 @@ func mergeAPIType(dst *APIType, fromList ...APIType) {
 	}
 }
 
+func mergeObjectType(dst *ObjectType, fromList ...ObjectType) {
+	for _, from := range fromList {
+		dst.Properties = mergeProperties(dst.Properties, from.Properties)
+		mergeUnimplement(&dst.MinProperties, from.MinProperties)
+		mergeUnimplement(&dst.MaxProperties, from.MaxProperties)
+		dst.AdditionalProperties = dst.AdditionalProperties && from.AdditionalProperties
+		mergeUnimplement(&dst.Discriminator, from.Discriminator)
+		mergeUnimplement(&dst.DiscriminatorValue, from.DiscriminatorValue)
+	}
+}
+
+// mergeProperties return properties of from with properties in dst override,
+// properties only in dst are appended in order
+func mergeProperties(dst Properties, fromList ...Properties) Properties {
+	for _, from := range fromList {
+		if len(from.Slice()) < 1 {
+			continue
+		}
+		if len(dst.Slice()) < 1 {
+			dst = from
+			continue
+		}
+
+		result := Properties{
+			mapdata: map[string]*Property{},
+		}
+		for _, property := range from.Slice() {
+			if override := dst.Map()[property.Name]; override != nil {
+				property = override
+			}
+			result.propertiesSliceData = append(result.propertiesSliceData, property)
+			result.mapdata[property.Name] = property
+		}
+		for _, property := range dst.Slice() {
+			if _, exist := result.mapdata[property.Name]; exist {
+				continue
+			}
+			result.propertiesSliceData = append(result.propertiesSliceData, property)
+			result.mapdata[property.Name] = property
+		}
+		dst = result
+	}
+	return dst
+}
+
 func mergeTypeDeclaration(dst *TypeDeclaration, fromList ...TypeDeclaration) {
 	for _, from := range fromList {
 		mergeUnimplement(&dst.Default, from.Default)
@@ -32,9 +78,11 @@ func mergeTypeDeclaration(dst *TypeDeclaration, fromList ...TypeDeclaration) {
 		// do not merge Example(s) field because Example will be filled by fillExample()
 		if dst.DisplayName == "" {
 			dst.DisplayName = from.DisplayName
+			dst.ScalarAnnotations = mergeScalarAnnotations(dst.ScalarAnnotations, from.ScalarAnnotations, "displayName")
 		}
 		if dst.Description == "" {
 			dst.Description = from.Description
+			dst.ScalarAnnotations = mergeScalarAnnotations(dst.ScalarAnnotations, from.ScalarAnnotations, "description")
 		}
 		dst.Annotations = mergeAnnotations(dst.Annotations, from.Annotations)
 		mergeUnimplement(&dst.Facets, from.Facets)
@@ -56,6 +104,20 @@ func mergeAnnotations(dst Annotations, fromList ...Annotations) Annotations {
 	return dst
 }
 
+// mergeScalarAnnotations return dst with annotations of facet in from, dst is
+// copied because it might be shared with other types
+func mergeScalarAnnotations(dst ScalarAnnotations, from ScalarAnnotations, facet string) ScalarAnnotations {
+	if from[facet] == nil {
+		return dst
+	}
+	result := ScalarAnnotations{}
+	for name, annotations := range dst {
+		result[name] = annotations
+	}
+	result[facet] = from[facet]
+	return result
+}
+
 func mergeUnimplement(dst *Unimplement, fromList ...Unimplement) {
 	for _, from := range fromList {
 		if dst.IsEmpty() {
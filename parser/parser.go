@@ -2,20 +2,20 @@ package parser
 
 import (
 	"bytes"
-	"io/ioutil"
+	"io/fs"
 	"path/filepath"
 	"reflect"
 
 	"github.com/tsaikd/KDGoLib/errutil"
-	"github.com/tsaikd/KDGoLib/futil"
 	"github.com/tsaikd/go-raml-parser/parser/parserConfig"
-	"github.com/tsaikd/yaml"
 )
 
 // NewParser create Parser instance
 func NewParser() Parser {
 	parser := &parserImpl{
-		errorTraceDistance: 4,
+		errorTraceDistance:  4,
+		ignoreUnusedLibrary: true,
+		ignoreUnusedType:    true,
 	}
 	return parser
 }
@@ -32,18 +32,79 @@ type Parser interface {
 	// Return RootDocument or an error if something went wrong.
 	ParseFile(filePath string) (rootdoc RootDocument, err error)
 
+	// ParseFS Parse a RAML file or directory in fsys, all included files,
+	// libraries and examples are loaded from fsys, cache is not used.
+	// Return RootDocument or an error if something went wrong.
+	ParseFS(fsys fs.FS, filePath string) (rootdoc RootDocument, err error)
+
 	// ParseFile Parse RAML from bynary data.
 	// Return RootDocument or an error if something went wrong.
 	ParseData(data []byte, workdir string) (rootdoc RootDocument, err error)
+
+	// ParseFragmentFile Parse a RAML fragment file, e.g. "#%RAML 1.0 DataType".
+	// Return Fragment or an error if something went wrong.
+	ParseFragmentFile(filePath string) (fragment Fragment, err error)
+
+	// ParseFragmentData Parse RAML fragment from binary data.
+	// Return Fragment or an error if something went wrong.
+	ParseFragmentData(data []byte, workdir string) (fragment Fragment, err error)
+
+	// ParseExtensionFile Parse a RAML overlay or extension file, which is
+	// merged into the master RAML declared by extends.
+	// Return the merged RootDocument or an error if something went wrong.
+	ParseExtensionFile(filePath string) (rootdoc RootDocument, err error)
 }
 
 type parserImpl struct {
 	cacheDirectory         string
+	cacheMaxBytes          int64
+	cacheStore             Cache
 	checkRAMLVersion       bool
 	checkValueOptions      []CheckValueOption
+	diagnosticCollector    *DiagnosticCollector
 	errorTraceDistance     int64
+	generateValueOptions   []GenerateValueOption
 	ignoreUnusedAnnotation bool
+	ignoreUnusedLibrary    bool
 	ignoreUnusedTrait      bool
+	ignoreUnusedType       bool
+	librarySearchPaths     []string
+	maxIncludeBytes        int64
+	maxIncludeDepth        int64
+	maxYAMLAliasExpansion  int64
+	resolver               Resolver
+	sandboxDirectory       string
+
+	// file loader of current parsing, create by each parsing
+	loader *fileLoader
+}
+
+// fileLoader return file loader of current parsing
+func (t *parserImpl) fileLoader() *fileLoader {
+	if t.loader == nil {
+		t.loader = newFileLoader(t)
+	}
+	return t.loader
+}
+
+// cache return the cache store, or nil if cache is disabled,
+// cache is not used if collecting diagnostics because diagnostics of
+// cached parsing result are not available, nor if custom resolver is set
+// because the resolved files can not be identified by config
+func (t parserImpl) cache() Cache {
+	if t.diagnosticCollector != nil {
+		return nil
+	}
+	if t.resolver != nil {
+		return nil
+	}
+	if t.cacheStore != nil {
+		return t.cacheStore
+	}
+	if t.cacheDirectory != "" {
+		return NewFSCache(t.cacheDirectory, t.cacheMaxBytes)
+	}
+	return nil
 }
 
 func (t *parserImpl) Config(config parserConfig.Enum, value interface{}) (err error) {
@@ -51,16 +112,40 @@ func (t *parserImpl) Config(config parserConfig.Enum, value interface{}) (err er
 	switch config {
 	case parserConfig.CacheDirectory:
 		field = &t.cacheDirectory
+	case parserConfig.CacheMaxBytes:
+		field = &t.cacheMaxBytes
+	case parserConfig.CacheStore:
+		field = &t.cacheStore
 	case parserConfig.CheckRAMLVersion:
 		field = &t.checkRAMLVersion
 	case parserConfig.CheckValueOptions:
 		field = &t.checkValueOptions
+	case parserConfig.DiagnosticCollector:
+		field = &t.diagnosticCollector
 	case parserConfig.ErrorTraceDistance:
 		field = &t.errorTraceDistance
+	case parserConfig.GenerateValueOptions:
+		field = &t.generateValueOptions
 	case parserConfig.IgnoreUnusedAnnotation:
 		field = &t.ignoreUnusedAnnotation
+	case parserConfig.IgnoreUnusedLibrary:
+		field = &t.ignoreUnusedLibrary
 	case parserConfig.IgnoreUnusedTrait:
 		field = &t.ignoreUnusedTrait
+	case parserConfig.IgnoreUnusedType:
+		field = &t.ignoreUnusedType
+	case parserConfig.LibrarySearchPaths:
+		field = &t.librarySearchPaths
+	case parserConfig.MaxIncludeBytes:
+		field = &t.maxIncludeBytes
+	case parserConfig.MaxIncludeDepth:
+		field = &t.maxIncludeDepth
+	case parserConfig.MaxYAMLAliasExpansion:
+		field = &t.maxYAMLAliasExpansion
+	case parserConfig.Resolver:
+		field = &t.resolver
+	case parserConfig.SandboxDirectory:
+		field = &t.sandboxDirectory
 	default:
 		return ErrorUnsupportedParserConfig1.New(nil, config)
 	}
@@ -77,40 +162,60 @@ func (t *parserImpl) Config(config parserConfig.Enum, value interface{}) (err er
 
 func (t *parserImpl) Get(config parserConfig.Enum) (value interface{}, err error) {
 	switch config {
+	case parserConfig.CacheDirectory:
+		return t.cacheDirectory, nil
+	case parserConfig.CacheMaxBytes:
+		return t.cacheMaxBytes, nil
+	case parserConfig.CacheStore:
+		return t.cacheStore, nil
 	case parserConfig.CheckRAMLVersion:
 		return t.checkRAMLVersion, nil
 	case parserConfig.CheckValueOptions:
 		return t.checkValueOptions, nil
+	case parserConfig.DiagnosticCollector:
+		return t.diagnosticCollector, nil
 	case parserConfig.ErrorTraceDistance:
 		return t.errorTraceDistance, nil
+	case parserConfig.GenerateValueOptions:
+		return t.generateValueOptions, nil
 	case parserConfig.IgnoreUnusedAnnotation:
 		return t.ignoreUnusedAnnotation, nil
+	case parserConfig.IgnoreUnusedLibrary:
+		return t.ignoreUnusedLibrary, nil
 	case parserConfig.IgnoreUnusedTrait:
 		return t.ignoreUnusedTrait, nil
+	case parserConfig.IgnoreUnusedType:
+		return t.ignoreUnusedType, nil
+	case parserConfig.LibrarySearchPaths:
+		return t.librarySearchPaths, nil
+	case parserConfig.MaxIncludeBytes:
+		return t.maxIncludeBytes, nil
+	case parserConfig.MaxIncludeDepth:
+		return t.maxIncludeDepth, nil
+	case parserConfig.MaxYAMLAliasExpansion:
+		return t.maxYAMLAliasExpansion, nil
+	case parserConfig.Resolver:
+		return t.resolver, nil
+	case parserConfig.SandboxDirectory:
+		return t.sandboxDirectory, nil
 	default:
 		return nil, ErrorUnsupportedParserConfig1.New(nil, config)
 	}
 }
 
 func (t parserImpl) ParseFile(filePath string) (rootdoc RootDocument, err error) {
-	var workdir string
-	var fileData []byte
-
-	if futil.IsDir(filePath) {
-		workdir = filePath
-		if fileData, err = LoadRAMLFromDir(filePath); err != nil {
-			return
-		}
-	} else {
-		workdir = filepath.Dir(filePath)
-		if fileData, err = ioutil.ReadFile(filePath); err != nil {
-			return
-		}
+	defer t.finishParse(&err)
+	fileData, workdir, location, err := t.loadRootFile(filePath)
+	if err != nil {
+		return
 	}
 
-	if t.cacheDirectory != "" {
+	if cache := t.cache(); cache != nil {
 		var saveFunc func(RootDocument)
-		if saveFunc, rootdoc, err = loadFromCache(filePath, fileData, t.cacheDirectory); err == nil {
+		if saveFunc, rootdoc, err = loadFromCache(filePath, cache, t.cacheConfig(), t.fileLoader()); err == nil {
+			// lazy references of recursive types are not cached, fill again
+			conf := newPostProcessConfig(&t, &rootdoc, nil, nil, nil, nil)
+			err = postProcessImplement(reflect.ValueOf(&rootdoc), fillPropertiesRef, conf)
 			return
 		}
 		if saveFunc != nil {
@@ -123,11 +228,49 @@ func (t parserImpl) ParseFile(filePath string) (rootdoc RootDocument, err error)
 		}
 	}
 
-	return t.ParseData(fileData, workdir)
+	return t.parseData(fileData, workdir, location)
+}
+
+func (t parserImpl) ParseFS(fsys fs.FS, filePath string) (rootdoc RootDocument, err error) {
+	defer t.finishParse(&err)
+	t.fileLoader().resolver = NewFSResolver(fsys)
+
+	fileData, workdir, location, err := t.loadRootFile(filePath)
+	if err != nil {
+		return
+	}
+
+	return t.parseData(fileData, workdir, location)
+}
+
+// loadRootFile load RAML file, or merge project files if filePath is a directory,
+// return the data, the working directory and the canonical location
+func (t *parserImpl) loadRootFile(filePath string) (fileData []byte, workdir string, location string, err error) {
+	loader := t.fileLoader()
+	if loader.isDir(filePath) {
+		workdir = filePath
+		location = directoryLocation(filePath)
+		if fileData, err = t.loadProject(filePath); err != nil {
+			return
+		}
+	} else {
+		workdir = filepath.Dir(filePath)
+		if fileData, location, err = loader.readFile("", filePath); err != nil {
+			return
+		}
+	}
+	return
 }
 
 func (t parserImpl) ParseData(data []byte, workdir string) (rootdoc RootDocument, err error) {
+	defer t.finishParse(&err)
+	return t.parseData(data, workdir, directoryLocation(workdir))
+}
+
+// parseData parse RAML data of the document at location
+func (t parserImpl) parseData(data []byte, workdir string, location string) (rootdoc RootDocument, err error) {
 	rootdoc.WorkingDirectory = workdir
+	rootdoc.location = location
 
 	if t.checkRAMLVersion {
 		if err = checkRAMLVersion(data); err != nil {
@@ -135,24 +278,133 @@ func (t parserImpl) ParseData(data []byte, workdir string) (rootdoc RootDocument
 		}
 	}
 
-	if err = yaml.Unmarshal(data, &rootdoc); err != nil {
-		line, _, ok := ParseYAMLError(err)
-		if !ok || t.errorTraceDistance < 0 {
-			return rootdoc, ErrorYAMLParseFailed.New(err)
+	// create loader before unmarshal to share with copies of parser
+	loader := t.fileLoader()
+	if err = t.unmarshalYAML(data, location, &rootdoc); err != nil {
+		return
+	}
+	fillPositions(loader, &rootdoc, location, "")
+
+	conf := newPostProcessConfig(&t, &rootdoc, nil, nil, nil, nil)
+	if err = postProcess(&rootdoc, conf); err != nil {
+		return
+	}
+
+	return
+}
+
+func (t parserImpl) ParseFragmentFile(filePath string) (fragment Fragment, err error) {
+	defer t.finishParse(&err)
+	fileData, location, err := t.fileLoader().readFile("", filePath)
+	if err != nil {
+		return
+	}
+	return t.parseFragmentData(fileData, filepath.Dir(filePath), location)
+}
+
+func (t parserImpl) ParseFragmentData(data []byte, workdir string) (fragment Fragment, err error) {
+	defer t.finishParse(&err)
+	return t.parseFragmentData(data, workdir, directoryLocation(workdir))
+}
+
+// parseFragmentData parse RAML fragment data of the document at location
+func (t parserImpl) parseFragmentData(data []byte, workdir string, location string) (fragment Fragment, err error) {
+	fragment.WorkingDirectory = workdir
+	if fragment.Kind, err = ParseFragmentHeader(data); err != nil {
+		return
+	}
+
+	switch fragment.Kind {
+	case FragmentKindRootDocument:
+		var rootdoc RootDocument
+		if rootdoc, err = t.parseData(data, workdir, location); err != nil {
+			return
 		}
+		fragment.RootDocument = &rootdoc
+		return
+	case FragmentKindOverlay, FragmentKindExtension:
+		var rootdoc RootDocument
+		if rootdoc, err = t.parseExtensionData(data, location); err != nil {
+			return
+		}
+		fragment.RootDocument = &rootdoc
+		return
+	}
 
-		extraInfo := GetLinesInRange(string(data), "\n", line+1, t.errorTraceDistance)
-		return rootdoc, ErrorYAMLParseFailed1.New(err, extraInfo)
+	// create loader before unmarshal to share with copies of parser
+	loader := t.fileLoader()
+	if err = t.unmarshalYAML(data, location, &fragment); err != nil {
+		return
+	}
+	for _, target := range []interface{}{
+		&fragment.Library,
+		fragment.DataType,
+		fragment.NamedExample,
+		fragment.Trait,
+		fragment.AnnotationType,
+	} {
+		fillPositions(loader, target, location, "")
 	}
 
-	conf := newPostProcessConfig(&t, &rootdoc, nil, nil, nil)
-	if err = postProcess(&rootdoc, conf); err != nil {
+	// declarations in a fragment are not required to be used
+	t.ignoreUnusedAnnotation = true
+	t.ignoreUnusedLibrary = true
+	t.ignoreUnusedTrait = true
+	t.ignoreUnusedType = true
+	rootdoc := &RootDocument{WorkingDirectory: workdir, location: location}
+	conf := newPostProcessConfig(&t, rootdoc, &fragment.Library, nil, nil, nil)
+	if err = postProcess(&fragment, conf); err != nil {
 		return
 	}
 
 	return
 }
 
+func (t parserImpl) ParseExtensionFile(filePath string) (rootdoc RootDocument, err error) {
+	defer t.finishParse(&err)
+	fileData, location, err := t.fileLoader().readFile("", filePath)
+	if err != nil {
+		return
+	}
+	return t.parseExtensionData(fileData, location)
+}
+
+// parseExtensionData parse overlay or extension data of the document at location
+func (t parserImpl) parseExtensionData(data []byte, location string) (rootdoc RootDocument, err error) {
+	tree, masterLocation, err := loadExtensionTree(t.fileLoader(), data, location, map[string]bool{})
+	if err != nil {
+		return
+	}
+
+	data = append([]byte(RAMLVersion+"\n"), tree.marshalFlow()...)
+	return t.parseData(data, filepath.Dir(masterLocation), masterLocation)
+}
+
+// unmarshalYAML unmarshal data into v with !include resolved from the
+// document at location, error trace lines are only available if no !include found
+func (t parserImpl) unmarshalYAML(data []byte, location string, v interface{}) (err error) {
+	loader := t.fileLoader()
+	loader.indexPositions(location, data)
+	resolved, err := resolveIncludes(loader, data, location)
+	if err != nil {
+		return
+	}
+
+	if err = loader.unmarshalYAML(resolved, v); err != nil {
+		if errutil.FactoryOf(err) == ErrorYAMLAliasExpansionExceeded1 {
+			return
+		}
+		line, _, ok := ParseYAMLError(err)
+		if !ok || t.errorTraceDistance < 0 || !bytes.Equal(resolved, data) {
+			return ErrorYAMLParseFailed.New(err)
+		}
+
+		extraInfo := GetLinesInRange(string(data), "\n", line+1, t.errorTraceDistance)
+		return ErrorYAMLParseFailed1.New(err, extraInfo)
+	}
+	return
+}
+
 func parserConfigSet(field interface{}, value interface{}) (err error) {
 	f := reflect.ValueOf(field)
 	v := reflect.ValueOf(value)
@@ -166,13 +418,5 @@ func parserConfigSet(field interface{}, value interface{}) (err error) {
 }
 
 func checkRAMLVersion(data []byte) (err error) {
-	buffer := bytes.NewBuffer(data)
-	firstLine, err := buffer.ReadString('\n')
-	if err != nil {
-		return
-	}
-	if firstLine[:10] != "#%RAML 1.0" {
-		return ErrorUnexpectedRAMLVersion2.New(nil, "#%RAML 1.0", firstLine[:10])
-	}
-	return nil
+	return checkFragmentKind(data, true, FragmentKindRootDocument)
 }
@@ -19,6 +19,42 @@ const (
 	IgnoreUnusedAnnotation
 	// RAML parser should ignore unused traits, type: bool, default: false
 	IgnoreUnusedTrait
+	// max total bytes of files included by RAML, set 0 to disable, type: int64, default: 0
+	MaxIncludeBytes
+	// max depth of nested !include and uses, set 0 to disable, type: int64, default: 0
+	MaxIncludeDepth
+	// max YAML nodes of a file after expanding aliases, set 0 to disable, type: int64, default: 0
+	MaxYAMLAliasExpansion
+	// restrict all file access of RAML parser in the directory, symbolic links
+	// escaping the directory are rejected, not applied to custom Resolver,
+	// set "" to disable, type: string, default: ""
+	SandboxDirectory
+	// resolve documents referenced by uses, !include and extends, use nil
+	// to read files relative to the including document, type: parser.Resolver, default: nil
+	Resolver
+	// directories to search library files of uses which are not found relative
+	// to the including document, type: []string, default: nil
+	LibrarySearchPaths
+	// max total bytes of cache entries in CacheDirectory, least recently used
+	// entries are evicted, set 0 to disable, type: int64, default: 0
+	CacheMaxBytes
+	// RAML parser cache store, CacheDirectory is used if nil, type: parser.Cache, default: nil
+	CacheStore
+	// collect all diagnostics of parsing instead of failing on the first
+	// error, warnings like unused traits do not fail parsing, cache is not
+	// used, type: *parser.DiagnosticCollector, default: nil
+	DiagnosticCollector
+	// RAML parser should ignore used libraries whose members are never
+	// referenced, type: bool, default: true
+	IgnoreUnusedLibrary
+	// RAML parser should ignore unused types, types only referenced by
+	// unused types are also unused, types named in resource types are
+	// treated as used, type: bool, default: true
+	IgnoreUnusedType
+	// options pass to GenerateValue, examples of types without declared
+	// examples are generated by GenerateValue only if not nil,
+	// type: []GenerateValueOption, default: nil
+	GenerateValueOptions
 )
 
 var factory = enumutil.NewEnumFactory().
@@ -28,6 +64,18 @@ var factory = enumutil.NewEnumFactory().
 	Add(ErrorTraceDistance, "ErrorTraceDistance").
 	Add(IgnoreUnusedAnnotation, "IgnoreUnusedAnnotation").
 	Add(IgnoreUnusedTrait, "IgnoreUnusedTrait").
+	Add(MaxIncludeBytes, "MaxIncludeBytes").
+	Add(MaxIncludeDepth, "MaxIncludeDepth").
+	Add(MaxYAMLAliasExpansion, "MaxYAMLAliasExpansion").
+	Add(SandboxDirectory, "SandboxDirectory").
+	Add(Resolver, "Resolver").
+	Add(LibrarySearchPaths, "LibrarySearchPaths").
+	Add(CacheMaxBytes, "CacheMaxBytes").
+	Add(CacheStore, "CacheStore").
+	Add(DiagnosticCollector, "DiagnosticCollector").
+	Add(IgnoreUnusedLibrary, "IgnoreUnusedLibrary").
+	Add(IgnoreUnusedType, "IgnoreUnusedType").
+	Add(GenerateValueOptions, "GenerateValueOptions").
 	Build()
 
 func (t Enum) String() string {
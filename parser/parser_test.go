@@ -3,8 +3,17 @@ package parser
 import (
 	"bytes"
 	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
 	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -746,6 +755,14 @@ func Test_ParseExampleIncludeBinaryFile(t *testing.T) {
 			}
 		}
 	}
+
+	// included binary file is counted once
+	require.NoError(parser.Config(parserConfig.MaxIncludeBytes, int64(14865)))
+	_, err = parser.ParseFile("./test-examples/example-include-binary-file.raml")
+	require.NoError(err)
+	require.NoError(parser.Config(parserConfig.MaxIncludeBytes, int64(14864)))
+	_, err = parser.ParseFile("./test-examples/example-include-binary-file.raml")
+	require.True(ErrorIncludeFile1.Match(err))
 }
 
 func Test_ParseObjectArray(t *testing.T) {
@@ -807,6 +824,1857 @@ func Test_ParseTrait(t *testing.T) {
 	}
 }
 
+func Test_ParseRecursiveType(t *testing.T) {
+	assert := assert.New(t)
+	assert.NotNil(assert)
+	require := require.New(t)
+	require.NotNil(require)
+
+	parser := NewParser()
+	require.NotNil(parser)
+
+	rootdoc, err := parser.ParseFile("./test-examples/recursive-type.raml")
+	require.NoError(err)
+	require.NotZero(rootdoc)
+
+	if apiType, ok := rootdoc.Types["Node"]; assert.True(ok) {
+		if property, ok := apiType.Properties.Map()["children"]; assert.True(ok) {
+			require.Equal("Node[]", property.Type)
+			require.Equal(TypeObject, property.NativeType)
+			require.True(property.IsArray)
+		}
+		if children, ok := apiType.Example.Value.Map["children"]; assert.True(ok) {
+			require.Equal(TypeArray, children.Type)
+		}
+	}
+	if resource, ok := rootdoc.Resources["/tree"]; assert.True(ok) {
+		if method, ok := resource.Methods["get"]; assert.True(ok) {
+			if response, ok := method.Responses[200]; assert.True(ok) {
+				if body, ok := response.Bodies["application/json"]; assert.True(ok) {
+					require.Equal("Node", body.Type)
+					require.Contains(body.Properties.Map(), "children")
+				}
+			}
+		}
+	}
+
+	value, err := NewValue(map[string]interface{}{
+		"name": "root",
+		"children": []interface{}{
+			map[string]interface{}{
+				"name": "child",
+				"children": []interface{}{
+					map[string]interface{}{
+						"name":     "grandchild",
+						"children": []interface{}{},
+					},
+				},
+			},
+		},
+	})
+	require.NoError(err)
+	require.NoError(CheckValueAPIType(*rootdoc.Types["Node"], value, CheckValueOptionAllowRequiredPropertyToBeEmpty(true)))
+
+	value.Map["children"].Array[0].Map["children"].Array[0].Map["name"] = &Value{Type: TypeInteger, Integer: 1}
+	require.Error(CheckValueAPIType(*rootdoc.Types["Node"], value, CheckValueOptionAllowRequiredPropertyToBeEmpty(true)))
+
+	value, err = NewValue(map[string]interface{}{
+		"name": "Alice",
+		"employer": map[string]interface{}{
+			"name": "Example",
+			"ceo": map[string]interface{}{
+				"name": 9527,
+			},
+		},
+	})
+	require.NoError(err)
+	require.Error(CheckValueAPIType(*rootdoc.Types["Person"], value))
+
+	_, err = parser.ParseData([]byte(strings.TrimSpace(`
+#%RAML 1.0
+types:
+    A: B
+    B: A
+	`)), ".")
+	require.Error(err)
+	require.True(ErrorTypeCyclicInheritance1.Match(err))
+}
+
+func Test_ParseTypeInheritance(t *testing.T) {
+	assert := assert.New(t)
+	assert.NotNil(assert)
+	require := require.New(t)
+	require.NotNil(require)
+
+	parser := NewParser()
+	require.NotNil(parser)
+
+	rootdoc, err := parser.ParseFile("./test-examples/type-inheritance.raml")
+	require.NoError(err)
+	require.NotZero(rootdoc)
+
+	if apiType, ok := rootdoc.Types["Employee"]; assert.True(ok) {
+		require.Equal("Person", apiType.Type)
+		require.Equal(TypeObject, apiType.NativeType)
+		if assert.Len(apiType.Properties.Slice(), 3) {
+			require.Equal("name", apiType.Properties.Slice()[0].Name)
+			require.Equal("age", apiType.Properties.Slice()[1].Name)
+			require.Equal("salary", apiType.Properties.Slice()[2].Name)
+		}
+		if property, ok := apiType.Properties.Map()["age"]; assert.True(ok) {
+			require.Equal(TypeInteger, property.Type)
+			require.False(property.Required)
+		}
+	}
+	if apiType, ok := rootdoc.Types["Manager"]; assert.True(ok) {
+		require.Equal(TypeObject, apiType.NativeType)
+		require.Len(apiType.Properties.Slice(), 4)
+		require.Contains(apiType.Properties.Map(), "name")
+		require.Contains(apiType.Properties.Map(), "salary")
+		require.Contains(apiType.Properties.Map(), "reports")
+	}
+	if resource, ok := rootdoc.Resources["/managers"]; assert.True(ok) {
+		if method, ok := resource.Methods["get"]; assert.True(ok) {
+			if response, ok := method.Responses[200]; assert.True(ok) {
+				if body, ok := response.Bodies["application/json"]; assert.True(ok) {
+					require.Len(body.Properties.Slice(), 4)
+				}
+			}
+		}
+	}
+
+	_, err = parser.ParseData([]byte(strings.TrimSpace(`
+#%RAML 1.0
+types:
+    Person:
+        type: object
+        properties:
+            name: string
+    Employee:
+        type: Person
+        properties:
+            salary: number
+        example:
+            salary: 1000.5
+	`)), ".")
+	require.Error(err)
+	require.True(ErrorRequiredProperty2.Match(err))
+
+	_, err = parser.ParseData([]byte(strings.TrimSpace(`
+#%RAML 1.0
+types:
+    Person:
+        type: object
+        properties:
+            age: integer
+    Employee:
+        type: Person
+        properties:
+            age: string
+	`)), ".")
+	require.Error(err)
+	require.True(ErrorPropertyOverrideIncompatible3.Match(err))
+
+	rootdoc, err = parser.ParseData([]byte(strings.TrimSpace(`
+#%RAML 1.0
+types:
+    Person:
+        type: object
+        properties:
+            name: string
+    Closed:
+        type: Person
+        additionalProperties: false
+    Alias: Person
+    ClosedAlias: Closed
+    Employee:
+        type: Alias
+        properties:
+            boss: Alias
+    Contractor:
+        type: ClosedAlias
+	`)), ".")
+	require.NoError(err)
+	require.True(rootdoc.Types["Alias"].AdditionalProperties)
+	require.True(rootdoc.Types["Employee"].AdditionalProperties)
+	require.True(rootdoc.Types["Employee"].Properties.Map()["boss"].AdditionalProperties)
+	require.False(rootdoc.Types["Closed"].AdditionalProperties)
+	require.False(rootdoc.Types["Contractor"].AdditionalProperties)
+}
+
+func Test_ParseLibraryTypes(t *testing.T) {
+	assert := assert.New(t)
+	assert.NotNil(assert)
+	require := require.New(t)
+	require.NotNil(require)
+
+	parser := NewParser()
+	require.NotNil(parser)
+
+	rootdoc, err := parser.ParseFile("./test-examples/library-types.raml")
+	require.NoError(err)
+	require.NotZero(rootdoc)
+
+	if apiType, err := rootdoc.GetType("people.Employee"); assert.NoError(err) {
+		require.Equal(TypeObject, apiType.NativeType)
+		require.Len(apiType.Properties.Slice(), 4)
+	}
+	if apiType, err := rootdoc.GetType("people.common.Email"); assert.NoError(err) {
+		require.Equal(TypeString, apiType.NativeType)
+	}
+	if apiType, ok := rootdoc.Types["Team"]; assert.True(ok) {
+		if property, ok := apiType.Properties.Map()["leader"]; assert.True(ok) {
+			require.Equal(TypeObject, property.NativeType)
+			require.Contains(property.Properties.Map(), "salary")
+		}
+		if property, ok := apiType.Properties.Map()["members"]; assert.True(ok) {
+			require.True(property.IsArray)
+			require.Contains(property.Properties.Map(), "email")
+		}
+		if property, ok := apiType.Properties.Map()["contact"]; assert.True(ok) {
+			require.Equal(TypeString, property.NativeType)
+		}
+	}
+	if apiType, ok := rootdoc.Types["Department"]; assert.True(ok) {
+		require.Equal(TypeObject, apiType.NativeType)
+		require.Contains(apiType.Properties.Map(), "id")
+		require.Contains(apiType.Properties.Map(), "name")
+	}
+	if resource, ok := rootdoc.Resources["/teams"]; assert.True(ok) {
+		if annotation, ok := resource.Annotations["people.common.audited"]; assert.True(ok) {
+			require.Equal("resource is audited", annotation.AnnotationType.Description)
+		}
+		if method, ok := resource.Methods["post"]; assert.True(ok) {
+			if body, ok := method.Bodies["application/json"]; assert.True(ok) {
+				require.Contains(body.Properties.Map(), "id")
+				require.Contains(body.Properties.Map(), "name")
+			}
+		}
+	}
+
+	if resourceType, err := rootdoc.GetResourceType("people.common.collection"); assert.NoError(err) {
+		require.Contains(resourceType.Map, "get")
+	}
+	if securityScheme, err := rootdoc.GetSecurityScheme("people.common.token"); assert.NoError(err) {
+		require.Equal("Pass Through", securityScheme.Map["type"].String)
+	}
+
+	_, err = parser.ParseData([]byte(strings.TrimSpace(`
+#%RAML 1.0
+types:
+    Team:
+        type: object
+        properties:
+            leader: people.Person
+	`)), ".")
+	require.Error(err)
+	require.True(ErrorUseNotFound1.Match(err))
+
+	_, err = parser.ParseData([]byte(strings.TrimSpace(`
+#%RAML 1.0
+uses:
+    people: library-types/people.raml
+/teams:
+    type: people.common.member
+	`)), "./test-examples")
+	require.True(ErrorResourceTypeNotFound1.Match(err))
+	require.Contains(err.Error(), `"people.common.member"`)
+
+	_, err = parser.ParseData([]byte(strings.TrimSpace(`
+#%RAML 1.0
+uses:
+    people: library-types/people.raml
+/teams:
+    get:
+        securedBy: [null, { people.common.oauth: { scopes: [ADMIN] } }]
+	`)), "./test-examples")
+	require.True(ErrorSecuritySchemeNotFound1.Match(err))
+	require.Contains(err.Error(), `"people.common.oauth"`)
+
+	_, err = parser.ParseFile("./test-examples/library-cyclic/api.raml")
+	require.Error(err)
+	require.True(ErrorIncludeCyclic1.Match(err))
+	require.Contains(err.Error(), "a.raml -> ")
+}
+
+func Test_ParseFragment(t *testing.T) {
+	assert := assert.New(t)
+	assert.NotNil(assert)
+	require := require.New(t)
+	require.NotNil(require)
+
+	parser := NewParser()
+	require.NotNil(parser)
+
+	kind, err := ParseFragmentHeader([]byte("#%RAML 1.0 AnnotationTypeDeclaration\r\n"))
+	require.NoError(err)
+	require.Equal(FragmentKindAnnotationTypeDeclaration, kind)
+	kind, err = ParseFragmentHeader([]byte("#%RAML 1.0"))
+	require.NoError(err)
+	require.Equal(FragmentKindRootDocument, kind)
+	_, err = ParseFragmentHeader([]byte("#%RAML 1.0 Unknown\n"))
+	require.True(ErrorUnknownFragmentKind1.Match(err))
+	_, err = ParseFragmentHeader([]byte("#%RAML 0.8\n"))
+	require.True(ErrorUnexpectedRAMLVersion2.Match(err))
+
+	fragment, err := parser.ParseFragmentFile("./test-examples/fragment/person.raml")
+	require.NoError(err)
+	require.Equal(FragmentKindDataType, fragment.Kind)
+	if assert.NotNil(fragment.DataType) {
+		require.Equal(TypeObject, fragment.DataType.NativeType)
+		require.Contains(fragment.DataType.Properties.Map(), "id")
+		require.Contains(fragment.DataType.Properties.Map(), "name")
+	}
+
+	fragment, err = parser.ParseFragmentData([]byte(strings.TrimSpace(`
+#%RAML 1.0 Trait
+description: paged collection
+queryParameters:
+    page: integer
+	`)), ".")
+	require.NoError(err)
+	require.Equal(FragmentKindTrait, fragment.Kind)
+	if assert.NotNil(fragment.Trait) {
+		require.Equal("paged collection", fragment.Trait.Description)
+		require.Contains(fragment.Trait.QueryParameters.Map(), "page")
+	}
+
+	fragment, err = parser.ParseFragmentData([]byte(strings.TrimSpace(`
+#%RAML 1.0 Library
+types:
+    Email: string
+annotationTypes:
+    audited:
+	`)), ".")
+	require.NoError(err)
+	require.Equal(FragmentKindLibrary, fragment.Kind)
+	require.Contains(fragment.Types, "Email")
+	require.Contains(fragment.AnnotationTypes, "audited")
+
+	_, err = parser.ParseFile("./test-examples/fragment/wrong-kind.raml")
+	require.Error(err)
+	require.True(ErrorLoadExternalLibrary1.Match(err))
+	require.Contains(err.Error(), `should be "Library" but got "DataType"`)
+}
+
+func Test_ParseExtension(t *testing.T) {
+	assert := assert.New(t)
+	assert.NotNil(assert)
+	require := require.New(t)
+	require.NotNil(require)
+
+	parser := NewParser()
+	require.NotNil(parser)
+
+	rootdoc, err := parser.ParseExtensionFile("./test-examples/extension/overlays/translation.raml")
+	require.NoError(err)
+	require.Equal("書籍 API", rootdoc.Title)
+	require.Equal("test-examples/extension", rootdoc.WorkingDirectory)
+	if resource, ok := rootdoc.Resources["/books"]; assert.True(ok) {
+		require.Contains(resource.Annotations, "deprecated")
+		if method, ok := resource.Methods["get"]; assert.True(ok) {
+			require.Equal("列出書籍", method.Description)
+			require.Contains(method.Responses, HTTPCode(200))
+		}
+	}
+
+	rootdoc, err = parser.ParseExtensionFile("./test-examples/extension/extension.raml")
+	require.NoError(err)
+	require.Equal("書籍 API", rootdoc.Title)
+	require.Equal("http://localhost/", rootdoc.BaseURI)
+	if resource, ok := rootdoc.Resources["/books"]; assert.True(ok) {
+		require.Contains(resource.Methods, "get")
+		require.Contains(resource.Methods, "post")
+	}
+
+	_, err = parser.ParseExtensionFile("./test-examples/extension/invalid-overlay.raml")
+	require.Error(err)
+	require.True(ErrorOverlayInvalidNodes1.Match(err))
+	require.Contains(err.Error(), "/~1books/post")
+	require.Contains(err.Error(), "/~1books/get/responses/200/body/application~1json/type")
+
+	fragment, err := parser.ParseFragmentFile("./test-examples/extension/extension.raml")
+	require.NoError(err)
+	require.Equal(FragmentKindExtension, fragment.Kind)
+	if assert.NotNil(fragment.RootDocument) {
+		require.Equal("http://localhost/", fragment.RootDocument.BaseURI)
+	}
+
+	_, err = parser.ParseExtensionFile("./test-examples/fragment/person.raml")
+	require.True(ErrorUnexpectedFragmentKind2.Match(err))
+}
+
+func Test_ParseInclude(t *testing.T) {
+	assert := assert.New(t)
+	assert.NotNil(assert)
+	require := require.New(t)
+	require.NotNil(require)
+
+	parser := NewParser()
+	require.NotNil(parser)
+
+	rootdoc, err := parser.ParseFile("./test-examples/include/api.raml")
+	require.NoError(err)
+	require.NotZero(rootdoc)
+
+	if documentation := rootdoc.Documentation.Array; assert.Len(documentation, 1) {
+		require.Equal("# Welcome\n", documentation[0].Map["content"].String)
+	}
+	if apiType, ok := rootdoc.Types["Person"]; assert.True(ok) {
+		require.Equal(TypeObject, apiType.Type)
+		if property, ok := apiType.Properties.Map()["address"]; assert.True(ok) {
+			require.Contains(property.Properties.Map(), "city")
+		}
+	}
+	if apiType, ok := rootdoc.Types["Schema"]; assert.True(ok) {
+		require.Contains(apiType.Type, "json-schema.org")
+	}
+	if trait, ok := rootdoc.Traits["paged"]; assert.True(ok) {
+		require.Contains(trait.QueryParameters.Map(), "page")
+	}
+	if resource, ok := rootdoc.Resources["/people"]; assert.True(ok) {
+		if annotation, ok := resource.Annotations["owner"]; assert.True(ok) {
+			require.Equal("Alice", annotation.Map["name"].String)
+		}
+		if method, ok := resource.Methods["get"]; assert.True(ok) {
+			if body, ok := method.Responses[200].Bodies["application/json"]; assert.True(ok) {
+				require.Equal(TypeInteger, body.Example.Value.Map["age"].Type)
+				require.Equal("Taipei", body.Example.Value.Map["address"].Map["city"].String)
+			}
+		}
+	}
+
+	_, err = parser.ParseFile("./test-examples/include/cyclic/api.raml")
+	require.Error(err)
+	require.True(ErrorIncludeCyclic1.Match(err))
+
+	rootdoc, err = parser.ParseData([]byte(strings.TrimSpace(`
+#%RAML 1.0
+title: Include Fragment API
+traits:
+    paged: !include paged.raml
+/people:
+    get:
+        is: [paged]
+	`)), "./test-examples/include")
+	require.NoError(err)
+	require.Contains(rootdoc.Traits, "paged")
+
+	_, err = parser.ParseData([]byte(strings.TrimSpace(`
+#%RAML 1.0
+title: Include Fragment API
+types:
+    Person: !include paged.raml
+	`)), "./test-examples/include")
+	require.Error(err)
+	require.True(ErrorIncludeFile1.Match(err))
+	require.Contains(err.Error(), ErrorUnexpectedFragmentKind2.New(nil, FragmentKindDataType, FragmentKindTrait).Error())
+}
+
+func Test_ParseSandbox(t *testing.T) {
+	require := require.New(t)
+	require.NotNil(require)
+
+	tempdir, err := ioutil.TempDir("", "go-raml-parser")
+	require.NoError(err)
+	defer os.RemoveAll(tempdir)
+
+	sandbox := filepath.Join(tempdir, "sandbox")
+	require.NoError(os.Mkdir(sandbox, 0755))
+	secret := filepath.Join(tempdir, "secret.txt")
+	require.NoError(ioutil.WriteFile(secret, []byte("secret"), 0644))
+	require.NoError(os.Symlink(secret, filepath.Join(sandbox, "link.txt")))
+	require.NoError(ioutil.WriteFile(filepath.Join(sandbox, "symlink.raml"), []byte(strings.TrimSpace(`
+#%RAML 1.0
+description: !include link.txt
+	`)), 0644))
+	require.NoError(ioutil.WriteFile(filepath.Join(sandbox, "parent.raml"), []byte(strings.TrimSpace(`
+#%RAML 1.0
+description: !include ../secret.txt
+	`)), 0644))
+
+	parser := NewParser()
+	require.NotNil(parser)
+
+	rootdoc, err := parser.ParseFile(filepath.Join(sandbox, "symlink.raml"))
+	require.NoError(err)
+	require.Equal("secret", rootdoc.Description)
+
+	require.NoError(parser.Config(parserConfig.SandboxDirectory, sandbox))
+	_, err = parser.ParseFile(filepath.Join(sandbox, "symlink.raml"))
+	require.True(ErrorIncludeFile1.Match(err))
+	require.Contains(err.Error(), "outside of sandbox")
+	_, err = parser.ParseFile(filepath.Join(sandbox, "parent.raml"))
+	require.True(ErrorIncludeFile1.Match(err))
+	require.Contains(err.Error(), "outside of sandbox")
+	_, err = parser.ParseFile(secret)
+	require.True(ErrorFileOutsideSandbox1.Match(err))
+	require.NoError(parser.Config(parserConfig.SandboxDirectory, ""))
+
+	require.NoError(parser.Config(parserConfig.MaxIncludeDepth, int64(1)))
+	_, err = parser.ParseFile("./test-examples/include/api.raml")
+	require.True(ErrorIncludeDepthExceeded1.Match(err))
+	require.NoError(parser.Config(parserConfig.MaxIncludeDepth, int64(2)))
+	_, err = parser.ParseFile("./test-examples/include/api.raml")
+	require.NoError(err)
+	require.NoError(parser.Config(parserConfig.MaxIncludeDepth, int64(0)))
+
+	require.NoError(parser.Config(parserConfig.MaxIncludeBytes, int64(100)))
+	_, err = parser.ParseFile("./test-examples/include/api.raml")
+	require.True(ErrorIncludeFile1.Match(err))
+	require.Contains(err.Error(), "exceed limit 100")
+	require.NoError(parser.Config(parserConfig.MaxIncludeBytes, int64(0)))
+
+	require.NoError(parser.Config(parserConfig.MaxYAMLAliasExpansion, int64(1000)))
+	_, err = parser.ParseData([]byte(strings.TrimSpace(`
+#%RAML 1.0
+title: &title Alias API
+description: *title
+	`)), ".")
+	require.NoError(err)
+	_, err = parser.ParseData([]byte(strings.TrimSpace(`
+#%RAML 1.0
+annotationTypes:
+    a: &a ["lol", "lol", "lol", "lol", "lol", "lol", "lol", "lol", "lol"]
+    b: &b [*a, *a, *a, *a, *a, *a, *a, *a, *a]
+    c: &c [*b, *b, *b, *b, *b, *b, *b, *b, *b]
+    d: &d [*c, *c, *c, *c, *c, *c, *c, *c, *c]
+    e: &e [*d, *d, *d, *d, *d, *d, *d, *d, *d]
+	`)), ".")
+	require.True(ErrorYAMLAliasExpansionExceeded1.Match(err))
+}
+
+func Test_ParseFS(t *testing.T) {
+	assert := assert.New(t)
+	assert.NotNil(assert)
+	require := require.New(t)
+	require.NotNil(require)
+
+	fsys := fstest.MapFS{
+		"specs/api.raml": &fstest.MapFile{Data: []byte(strings.TrimSpace(`
+#%RAML 1.0
+uses:
+    lib: libs/lib.raml
+types:
+    Avatar:
+        type: file
+        example: !include avatar.png
+/people:
+    get:
+        responses:
+            200:
+                body:
+                    application/json:
+                        type: lib.Person
+		`))},
+		"specs/avatar.png": &fstest.MapFile{Data: []byte{0x89, 0x50, 0x4e, 0x47, 0xff}},
+		"specs/libs/lib.raml": &fstest.MapFile{Data: []byte(strings.TrimSpace(`
+#%RAML 1.0 Library
+types:
+    Person: !include person.raml
+		`))},
+		"specs/libs/person.raml": &fstest.MapFile{Data: []byte(strings.TrimSpace(`
+#%RAML 1.0 DataType
+type: object
+properties:
+    name: string
+		`))},
+		"dir/10-main.raml":     &fstest.MapFile{Data: []byte("#%RAML 1.0\ntitle: Dir API\n")},
+		"dir/20-resource.raml": &fstest.MapFile{Data: []byte("/dir:\n    get:\n")},
+	}
+
+	parser := NewParser()
+	require.NotNil(parser)
+
+	rootdoc, err := parser.ParseFS(fsys, "specs/api.raml")
+	require.NoError(err)
+	if apiType, ok := rootdoc.Types["Avatar"]; assert.True(ok) {
+		require.Equal([]byte{0x89, 0x50, 0x4e, 0x47, 0xff}, apiType.Example.Value.Binary)
+	}
+	if apiType, err := rootdoc.GetType("lib.Person"); assert.NoError(err) {
+		require.Contains(apiType.Properties.Map(), "name")
+	}
+
+	rootdoc, err = parser.ParseFS(fsys, "dir")
+	require.NoError(err)
+	require.Equal("Dir API", rootdoc.Title)
+	require.Contains(rootdoc.Resources, "/dir")
+
+	_, err = parser.ParseFS(fsys, "specs/missing.raml")
+	require.Error(err)
+}
+
+// registryResolver resolve references prefixed by "registry:" from packages,
+// other references are resolved by file resolver
+type registryResolver struct {
+	packages map[string]string
+	calls    *[]string
+}
+
+func (t registryResolver) Resolve(location string, reference string) (data []byte, canonical string, err error) {
+	*t.calls = append(*t.calls, location+" => "+reference)
+	canonical = reference
+	if !strings.HasPrefix(reference, "registry:") && strings.HasPrefix(location, "registry:") {
+		canonical = path.Join(path.Dir(location), reference)
+	}
+	if !strings.HasPrefix(canonical, "registry:") {
+		return NewFileResolver("").Resolve(location, reference)
+	}
+	content, ok := t.packages[canonical]
+	if !ok {
+		return nil, canonical, os.ErrNotExist
+	}
+	return []byte(content), canonical, nil
+}
+
+func Test_ParseResolver(t *testing.T) {
+	assert := assert.New(t)
+	assert.NotNil(assert)
+	require := require.New(t)
+	require.NotNil(require)
+
+	calls := []string{}
+	resolver := registryResolver{
+		packages: map[string]string{
+			"registry:common/lib.raml": strings.TrimSpace(`
+#%RAML 1.0 Library
+types:
+    Person: !include person.raml
+			`),
+			"registry:common/person.raml": strings.TrimSpace(`
+#%RAML 1.0 DataType
+type: object
+properties:
+    name: string
+			`),
+		},
+		calls: &calls,
+	}
+
+	parser := NewParser()
+	require.NotNil(parser)
+	value, err := parser.Get(parserConfig.Resolver)
+	require.NoError(err)
+	require.Nil(value)
+	require.NoError(parser.Config(parserConfig.Resolver, Resolver(resolver)))
+
+	rootdoc, err := parser.ParseData([]byte(strings.TrimSpace(`
+#%RAML 1.0
+title: Registry API
+description: !include intro.md
+uses:
+    common: registry:common/lib.raml
+/people:
+    get:
+        responses:
+            200:
+                body:
+                    application/json:
+                        type: common.Person
+	`)), filepath.Join("test-examples", "include"))
+	require.NoError(err)
+	require.Equal("# Welcome", strings.TrimSpace(rootdoc.Description))
+	if apiType, err := rootdoc.GetType("common.Person"); assert.NoError(err) {
+		require.Contains(apiType.Properties.Map(), "name")
+	}
+	location := filepath.Join("test-examples", "include") + string(filepath.Separator)
+	require.Contains(calls, location+" => intro.md")
+	require.Contains(calls, location+" => registry:common/lib.raml")
+	require.Contains(calls, "registry:common/lib.raml => person.raml")
+
+	_, err = parser.ParseData([]byte(strings.TrimSpace(`
+#%RAML 1.0
+uses:
+    missing: registry:missing.raml
+	`)), ".")
+	require.True(ErrorLoadExternalLibrary1.Match(err))
+	require.Contains(err.Error(), "registry:missing.raml")
+}
+
+func Test_ParseLibrarySearchPaths(t *testing.T) {
+	assert := assert.New(t)
+	assert.NotNil(assert)
+	require := require.New(t)
+	require.NotNil(require)
+
+	parser := NewParser()
+	require.NotNil(parser)
+
+	_, err := parser.ParseFile("./test-examples/search-path/api.raml")
+	require.True(ErrorLoadExternalLibrary1.Match(err))
+
+	shared := filepath.Join("test-examples", "search-path", "shared")
+	require.NoError(parser.Config(parserConfig.LibrarySearchPaths, []string{"test-examples/missing", shared}))
+	rootdoc, err := parser.ParseFile("./test-examples/search-path/api.raml")
+	require.NoError(err)
+	if apiType, err := rootdoc.GetType("common.Person"); assert.NoError(err) {
+		require.Contains(apiType.Properties.Map(), "name")
+	}
+	require.Equal(map[string]string{
+		"common":      filepath.Join(shared, "common", "common.raml"),
+		"common.base": filepath.Join(shared, "common", "base.raml"),
+	}, rootdoc.LibraryLocations())
+}
+
+func Test_ParseCacheDependencies(t *testing.T) {
+	assert := assert.New(t)
+	assert.NotNil(assert)
+	require := require.New(t)
+	require.NotNil(require)
+
+	tempdir, err := ioutil.TempDir("", "go-raml-parser")
+	require.NoError(err)
+	defer os.RemoveAll(tempdir)
+
+	writeFile := func(name string, data string) {
+		require.NoError(ioutil.WriteFile(filepath.Join(tempdir, name), []byte(strings.TrimSpace(data)), 0644))
+	}
+	writeFile("api.raml", `
+#%RAML 1.0
+title: Cache API
+uses:
+    lib: lib.raml
+types:
+    Avatar:
+        type: file
+        example: !include avatar.bin
+	`)
+	writeFile("lib.raml", `
+#%RAML 1.0 Library
+types:
+    Person:
+        type: object
+        properties:
+            name: string
+	`)
+	writeFile("avatar.bin", "\xff\x00")
+
+	parser := NewParser()
+	require.NotNil(parser)
+	cacheDirectory := filepath.Join(tempdir, ".cache")
+	require.NoError(parser.Config(parserConfig.CacheDirectory, cacheDirectory))
+	apiPath := filepath.Join(tempdir, "api.raml")
+
+	rootdoc, err := parser.ParseFile(apiPath)
+	require.NoError(err)
+	impl := parser.(*parserImpl)
+	_, _, err = loadFromCache(apiPath, NewFSCache(cacheDirectory, 0), impl.cacheConfig(), newFileLoader(impl))
+	require.NoError(err)
+	if apiType, err := rootdoc.GetType("lib.Person"); assert.NoError(err) {
+		require.Contains(apiType.Properties.Map(), "name")
+	}
+
+	// included library changed
+	writeFile("lib.raml", `
+#%RAML 1.0 Library
+types:
+    Person:
+        type: object
+        properties:
+            email: string
+	`)
+	rootdoc, err = parser.ParseFile(apiPath)
+	require.NoError(err)
+	if apiType, err := rootdoc.GetType("lib.Person"); assert.NoError(err) {
+		require.Contains(apiType.Properties.Map(), "email")
+	}
+
+	// included binary example changed
+	writeFile("avatar.bin", "\xff\x01")
+	rootdoc, err = parser.ParseFile(apiPath)
+	require.NoError(err)
+	require.Equal([]byte("\xff\x01"), rootdoc.Types["Avatar"].Example.Value.Binary)
+
+	// parser config changed
+	writeFile("api.raml", `
+title: Cache API
+	`)
+	_, err = parser.ParseFile(apiPath)
+	require.NoError(err)
+	require.NoError(parser.Config(parserConfig.CheckRAMLVersion, true))
+	_, err = parser.ParseFile(apiPath)
+	require.True(ErrorUnexpectedRAMLVersion2.Match(err))
+
+	// options of the same value but different types
+	require.NoError(parser.Config(parserConfig.CheckValueOptions, []CheckValueOption{
+		CheckValueOptionAllowIntegerToBeNumber(true),
+	}))
+	config := impl.cacheConfig()
+	require.NoError(parser.Config(parserConfig.CheckValueOptions, []CheckValueOption{
+		CheckValueOptionAllowArrayToBeNull(true),
+	}))
+	require.NotEqual(config, impl.cacheConfig())
+
+	// cache is disabled with custom resolver
+	require.NotNil(impl.cache())
+	require.NoError(parser.Config(parserConfig.Resolver, NewFileResolver("")))
+	require.Nil(impl.cache())
+}
+
+func Test_ParseProject(t *testing.T) {
+	assert := assert.New(t)
+	assert.NotNil(assert)
+	require := require.New(t)
+	require.NotNil(require)
+
+	parser := NewParser()
+	require.NotNil(parser)
+	require.NoError(parser.Config(parserConfig.CheckRAMLVersion, true))
+
+	rootdoc, err := parser.ParseFile("./test-examples/project")
+	require.NoError(err)
+	require.Equal("Project API", rootdoc.Title)
+	require.Contains(rootdoc.Resources, "/people")
+	require.Contains(rootdoc.Uses, "common")
+	require.Contains(rootdoc.Uses, "shared")
+	if apiType, err := rootdoc.GetType("Person"); assert.NoError(err) {
+		require.Contains(apiType.Properties.Map(), "name")
+	}
+	require.Contains(rootdoc.Types, "People")
+
+	rootdoc, err = parser.ParseFile("./test-examples/raml-from-dir")
+	require.NoError(err)
+	require.Equal("Load raml from directory", rootdoc.Title)
+	require.Contains(rootdoc.Types, "User")
+
+	_, err = parser.ParseFile("./test-examples/project-conflict")
+	require.True(ErrorProjectConflict3.Match(err))
+	require.Contains(err.Error(), "/types/Person")
+	require.Contains(err.Error(), filepath.Join("test-examples", "project-conflict", "a.raml"))
+	require.Contains(err.Error(), filepath.Join("test-examples", "project-conflict", "b.raml"))
+
+	_, err = parser.ParseFile("./test-examples/project-invalid")
+	require.True(ErrorLoadProjectFile1.Match(err))
+	require.Contains(err.Error(), filepath.Join("test-examples", "project-invalid", "b.raml"))
+	require.Contains(err.Error(), "properties: invalid")
+}
+
+func Test_ParsePosition(t *testing.T) {
+	assert := assert.New(t)
+	assert.NotNil(assert)
+	require := require.New(t)
+	require.NotNil(require)
+
+	parser := NewParser()
+	require.NotNil(parser)
+
+	_, err := parser.ParseFile("./test-examples/position/undefined-type.raml")
+	require.True(ErrorTypeUndefined1.Match(err))
+	position, ok := ErrorPosition(err)
+	require.True(ok)
+	require.Equal(Position{
+		File:   filepath.Join("test-examples", "position", "undefined-type.raml"),
+		Line:   8,
+		Column: 7,
+	}, position)
+	require.Contains(err.Error(), position.String())
+
+	// error of type declared by type
+	_, err = parser.ParseFile("./test-examples/position/undefined-declared-type.raml")
+	require.True(ErrorTypeUndefined1.Match(err))
+	position, ok = ErrorPosition(err)
+	require.True(ok)
+	require.Equal(Position{
+		File:   filepath.Join("test-examples", "position", "undefined-declared-type.raml"),
+		Line:   8,
+		Column: 3,
+	}, position)
+
+	// error in included file
+	_, err = parser.ParseFile("./test-examples/position/include.raml")
+	require.True(ErrorTypeUndefined1.Match(err))
+	position, ok = ErrorPosition(err)
+	require.True(ok)
+	require.Equal(Position{
+		File:   filepath.Join("test-examples", "position", "user.raml"),
+		Line:   5,
+		Column: 3,
+	}, position)
+
+	// error in library file
+	_, err = parser.ParseFile("./test-examples/position/library.raml")
+	require.True(ErrorTypeUndefined1.Match(err))
+	position, ok = ErrorPosition(err)
+	require.True(ok)
+	require.Equal(Position{
+		File:   filepath.Join("test-examples", "position", "lib.raml"),
+		Line:   7,
+		Column: 7,
+	}, position)
+
+	rootdoc, err := parser.ParseFile("./test-examples/trait.raml")
+	require.NoError(err)
+	if resource := rootdoc.Resources["/user"]; assert.NotNil(resource) {
+		require.Equal(8, resource.Position.Line)
+		require.Equal(1, resource.Position.Column)
+		if method := resource.Methods["get"]; assert.NotNil(method) {
+			require.Equal(9, method.Position.Line)
+			require.Equal(5, method.Position.Column)
+		}
+	}
+
+	// positions of project nodes are in the declaring files
+	rootdoc, err = parser.ParseFile("./test-examples/project")
+	require.NoError(err)
+	if apiType, err := rootdoc.GetType("Person"); assert.NoError(err) {
+		require.Equal(filepath.Join("test-examples", "project", "types", "person.raml"), apiType.Position.File)
+	}
+
+	_, err = parser.ParseData([]byte(`#%RAML 1.0
+title: Position API
+types:
+  User:
+    properties:
+      "group": Group
+`), ".")
+	require.True(ErrorTypeUndefined1.Match(err), "%v", err)
+	position, ok = ErrorPosition(err)
+	require.True(ok)
+	require.Equal(Position{Line: 6, Column: 7}, position)
+}
+
+func Test_IndexYAMLPositions(t *testing.T) {
+	require := require.New(t)
+	require.NotNil(require)
+
+	positions := indexYAMLPositions([]byte(`#%RAML 1.0
+title: |
+  key: not a node
+items:
+- name: a
+  value: 1
+-
+  name: b
+"quoted/key": {a: 1}
+nested:
+  # comment
+  child: true
+`))
+	require.Equal(Position{Line: 1, Column: 1}, positions[""])
+	require.Equal(Position{Line: 2, Column: 1}, positions["/title"])
+	require.NotContains(positions, "/key")
+	require.NotContains(positions, "/title/key")
+	require.Equal(Position{Line: 5, Column: 1}, positions["/items/0"])
+	require.Equal(Position{Line: 5, Column: 3}, positions["/items/0/name"])
+	require.Equal(Position{Line: 6, Column: 3}, positions["/items/0/value"])
+	require.Equal(Position{Line: 7, Column: 1}, positions["/items/1"])
+	require.Equal(Position{Line: 8, Column: 3}, positions["/items/1/name"])
+	require.Equal(Position{Line: 9, Column: 1}, positions["/quoted~1key"])
+	require.Equal(Position{Line: 12, Column: 3}, positions["/nested/child"])
+}
+
+func Test_ParseDiagnostics(t *testing.T) {
+	require := require.New(t)
+	require.NotNil(require)
+
+	parser := NewParser()
+	require.NotNil(parser)
+
+	// fail on the first error without collector
+	_, err := parser.ParseFile("./test-examples/diagnostics.raml")
+	require.True(ErrorUnusedTrait1.Match(err))
+
+	collector := NewDiagnosticCollector()
+	require.NoError(parser.Config(parserConfig.DiagnosticCollector, collector))
+	value, err := parser.Get(parserConfig.DiagnosticCollector)
+	require.NoError(err)
+	require.Equal(collector, value)
+
+	file := filepath.Join("test-examples", "diagnostics.raml")
+	_, err = parser.ParseFile("./test-examples/diagnostics.raml")
+	require.True(ErrorPropertyTypeMismatch3.Match(err))
+	require.True(collector.HasErrors())
+	diagnostics := collector.Diagnostics()
+	require.Len(diagnostics, 4)
+	require.Equal(SeverityWarning, diagnostics[0].Severity)
+	require.Equal("trait-unused", diagnostics[0].Code)
+	require.Equal(`Trait "paged" is unused`, diagnostics[0].Message)
+	require.Equal(Position{File: file, Line: 5, Column: 3}, diagnostics[0].Position)
+	require.Equal(SeverityWarning, diagnostics[1].Severity)
+	require.Equal(`Trait "secured" is unused`, diagnostics[1].Message)
+	require.Equal(SeverityError, diagnostics[2].Severity)
+	require.Equal("property-type-mismatch", diagnostics[2].Code)
+	require.Equal(Position{File: file, Line: 11, Column: 3}, diagnostics[2].Position)
+	require.Equal(SeverityError, diagnostics[3].Severity)
+	require.Equal(Position{File: file, Line: 18, Column: 3}, diagnostics[3].Position)
+	require.Equal(file+`:5:3: warning: Trait "paged" is unused [trait-unused]`, diagnostics[0].String())
+	data, err := json.Marshal(diagnostics[0])
+	require.NoError(err)
+	require.Contains(string(data), `"severity":"warning"`)
+
+	// warnings do not block the returned RootDocument
+	collector.Reset()
+	rootdoc, err := parser.ParseFile("./test-examples/check-unused-trait.raml")
+	require.NoError(err)
+	require.Contains(rootdoc.Traits, "UsedTrait")
+	require.False(collector.HasErrors())
+	diagnostics = collector.Diagnostics()
+	require.Len(diagnostics, 1)
+	require.Equal(SeverityWarning, diagnostics[0].Severity)
+	require.True(ErrorUnusedTrait1.Match(diagnostics[0].Err))
+
+	// failed fill stages do not stop next stages
+	collector.Reset()
+	_, err = parser.ParseFile("./test-examples/diagnostics-stages.raml")
+	require.True(ErrorTypeUndefined1.Match(err))
+	codes := map[string]int{}
+	for _, diagnostic := range collector.Diagnostics() {
+		require.Equal(SeverityError, diagnostic.Severity)
+		codes[diagnostic.Code]++
+	}
+	require.Equal(3, codes["type-undefined"])
+	require.Equal(2, codes["typo"])
+	require.NotZero(codes["property-type-mismatch"])
+
+	// errors outside post processing are collected
+	collector.Reset()
+	_, err = parser.ParseFile("./test-examples/not-exist.raml")
+	require.Error(err)
+	require.True(collector.HasErrors())
+	require.Len(collector.Diagnostics(), 1)
+}
+
+func Test_ParseErrorAs(t *testing.T) {
+	require := require.New(t)
+	require.NotNil(require)
+
+	parser := NewParser()
+	require.NotNil(parser)
+
+	_, err := parser.ParseFile("./test-examples/position/library.raml")
+	require.True(ErrorTypeUndefined1.Match(err))
+	undefinedErr := &UndefinedError{}
+	require.True(errors.As(err, &undefinedErr))
+	require.Equal(UndefinedType, undefinedErr.Kind)
+	require.Equal("Group", undefinedErr.Name)
+	position, ok := undefinedErr.Position()
+	require.True(ok)
+	require.Equal(filepath.Join("test-examples", "position", "lib.raml"), position.File)
+
+	// typed error wrapped by other errors
+	err = exportError(ErrorLoadExternalLibrary1.New(undefinedErr, "lib.raml"))
+	require.True(ErrorLoadExternalLibrary1.Match(err))
+	undefinedErr = &UndefinedError{}
+	require.True(errors.As(err, &undefinedErr))
+	require.Equal("Group", undefinedErr.Name)
+
+	_, err = parser.ParseData([]byte(`#%RAML 1.0
+title: ErrorAs API
+/users:
+  get:
+    unknown: facet
+`), ".")
+	require.True(ErrorTypo2.Match(err))
+	facetErr := &UnknownFacetError{}
+	require.True(errors.As(err, &facetErr))
+	require.Equal("Method", facetErr.Node)
+	require.Equal([]string{"unknown"}, facetErr.Facets)
+
+	_, err = parser.ParseData([]byte(`#%RAML 1.0
+title: ErrorAs API
+types:
+  User:
+    properties:
+      age: integer
+    example:
+      age: old
+`), ".")
+	require.True(ErrorPropertyTypeMismatch3.Match(err))
+	mismatchErr := &TypeMismatchError{}
+	require.True(errors.As(err, &mismatchErr))
+	require.Equal([]string{"age"}, mismatchErr.Path)
+	require.Equal(TypeInteger, mismatchErr.Expected)
+	require.Equal(TypeString, mismatchErr.Actual)
+}
+
+func Test_ParseUnusedTypeAndLibrary(t *testing.T) {
+	require := require.New(t)
+	require.NotNil(require)
+
+	parser := NewParser()
+	require.NotNil(parser)
+
+	// unused types and libraries are ignored by default
+	value, err := parser.Get(parserConfig.IgnoreUnusedType)
+	require.NoError(err)
+	require.Equal(true, value)
+	value, err = parser.Get(parserConfig.IgnoreUnusedLibrary)
+	require.NoError(err)
+	require.Equal(true, value)
+	_, err = parser.ParseFile("./test-examples/unused/api.raml")
+	require.NoError(err)
+
+	require.NoError(parser.Config(parserConfig.IgnoreUnusedType, false))
+	_, err = parser.ParseFile("./test-examples/unused/api.raml")
+	require.True(ErrorUnusedType1.Match(err))
+	require.Contains(err.Error(), `Type "Friend" is unused`)
+
+	require.NoError(parser.Config(parserConfig.IgnoreUnusedLibrary, false))
+	collector := NewDiagnosticCollector()
+	require.NoError(parser.Config(parserConfig.DiagnosticCollector, collector))
+	_, err = parser.ParseFile("./test-examples/unused/api.raml")
+	require.NoError(err)
+	messages := []string{}
+	for _, diagnostic := range collector.Diagnostics() {
+		require.Equal(SeverityWarning, diagnostic.Severity)
+		messages = append(messages, diagnostic.String())
+	}
+	file := filepath.Join("test-examples", "unused", "api.raml")
+	require.Equal([]string{
+		file + `:5:5: warning: Library "unused" is unused [library-unused]`,
+		file + `:16:5: warning: Type "Orphan" is unused [type-unused]`,
+		file + `:20:5: warning: Type "Friend" is unused [type-unused]`,
+		filepath.Join("test-examples", "unused", "unused.raml") + `:3:5: warning: Type "unused.Thing" is unused [type-unused]`,
+	}, messages)
+}
+
+func Test_ParseTypo(t *testing.T) {
+	require := require.New(t)
+	require.NotNil(require)
+
+	parser := NewParser()
+	require.NotNil(parser)
+
+	testcases := []struct {
+		raml       string
+		node       string
+		facet      string
+		suggestion string
+	}{
+		{
+			raml: `
+title: Typo API
+mediatype: application/json
+`,
+			node:       "RootDocument",
+			facet:      "mediatype",
+			suggestion: "mediaType",
+		},
+		{
+			raml: `
+title: Typo API
+/users:
+  descripton: users
+`,
+			node:       "Resource",
+			facet:      "descripton",
+			suggestion: "description",
+		},
+		{
+			raml: `
+title: Typo API
+/users:
+  gett:
+    description: list users
+`,
+			node:       "Resource",
+			facet:      "gett",
+			suggestion: "get",
+		},
+		{
+			raml: `
+title: Typo API
+/users:
+  get:
+    responses:
+      200:
+        bdy:
+          application/json:
+            type: string
+`,
+			node:       "Response",
+			facet:      "bdy",
+			suggestion: "body",
+		},
+		{
+			raml: `
+title: Typo API
+mediaType: application/json
+/users:
+  post:
+    body:
+      propertes:
+        name: string
+`,
+			node:       "APIType",
+			facet:      "propertes",
+			suggestion: "properties",
+		},
+		{
+			raml: `
+title: Typo API
+types:
+  User:
+    properties:
+      name:
+        type: string
+        requierd: false
+`,
+			node:       "APIType",
+			facet:      "requierd",
+			suggestion: "required",
+		},
+		{
+			raml: `
+title: Typo API
+traits:
+  paged:
+    usage: apply to collections
+    queryParamters:
+      page: integer
+/users:
+  get:
+    is: [paged]
+`,
+			node:       "Trait",
+			facet:      "queryParamters",
+			suggestion: "queryParameters",
+		},
+		{
+			raml: `
+title: Typo API
+/users:
+  get:
+    headers:
+      X-Request-Id:
+        type: string
+        patern: "^[0-9a-f]+$"
+`,
+			node:       "APIType",
+			facet:      "patern",
+			suggestion: "pattern",
+		},
+		{
+			raml: `
+title: Typo API
+/users:
+  get:
+    queryParameters:
+      page:
+        type: integer
+        minimun: 1
+`,
+			node:       "APIType",
+			facet:      "minimun",
+			suggestion: "minimum",
+		},
+		{
+			raml: `
+title: Typo API
+/users:
+  get:
+    responses:
+      200:
+        headers:
+          X-Total:
+            type: integer
+            descripton: total count
+`,
+			node:       "APIType",
+			facet:      "descripton",
+			suggestion: "description",
+		},
+		{
+			raml: `
+title: Typo API
+annotationTypes:
+  deprecated:
+    type: boolean
+    allowedTarget: Method
+`,
+			node:       "AnnotationType",
+			facet:      "allowedTarget",
+			suggestion: "allowedTargets",
+		},
+		{
+			raml: `
+title: Typo API
+types:
+  User:
+    type: object
+    xyzzy: true
+`,
+			node:  "APIType",
+			facet: "xyzzy",
+		},
+	}
+	for _, testcase := range testcases {
+		_, err := parser.ParseData([]byte("#%RAML 1.0"+testcase.raml), ".")
+		require.True(ErrorTypo2.Match(err), testcase.raml)
+		facetErr := &UnknownFacetError{}
+		require.True(errors.As(err, &facetErr))
+		require.Equal(testcase.node, facetErr.Node, testcase.raml)
+		require.Equal([]string{testcase.facet}, facetErr.Facets)
+		if testcase.suggestion == "" {
+			require.Empty(facetErr.Suggestions)
+			continue
+		}
+		require.Equal(testcase.suggestion, facetErr.Suggestions[testcase.facet])
+		require.Contains(err.Error(), fmt.Sprintf("did you mean %q instead of %q?", testcase.suggestion, testcase.facet))
+	}
+
+	// valid facets of wrapped nodes and user-defined facets are not typo
+	_, err := parser.ParseData([]byte(`#%RAML 1.0
+title: Typo API
+annotationTypes:
+  deprecated:
+    type: boolean
+    allowedTargets: Method
+traits:
+  paged:
+    usage: apply to collections
+    queryParameters:
+      page: integer
+types:
+  Date:
+    type: string
+    facets:
+      noHolidays?: boolean
+  WorkingDay:
+    type: Date
+    noHolidays: true
+  Age:
+    type: integer
+    minimum: 0
+    maximum: 150
+/users/{id}:
+  uriParameters:
+    id:
+      type: string
+      required: true
+  get:
+    (deprecated): true
+    is: [paged]
+`), ".")
+	require.NoError(err)
+}
+
+func Test_ParseAnnotationValue(t *testing.T) {
+	require := require.New(t)
+	require.NotNil(require)
+
+	parser := NewParser()
+	require.NotNil(parser)
+
+	_, err := parser.ParseFile("./test-examples/annotation-value.raml")
+	require.NoError(err)
+
+	data, err := ioutil.ReadFile("./test-examples/annotation-value.raml")
+	require.NoError(err)
+
+	testcases := []struct {
+		old     string
+		new     string
+		message string
+	}{
+		{
+			old:     "(rateLimit): 5",
+			new:     "(rateLimit): five",
+			message: `Property type mismatch, expected "integer" but got "string"; in value of annotation "rateLimit"`,
+		},
+		{
+			old:     "(tags): [public, searchable]",
+			new:     "(tags): [public, 1]",
+			message: `array element 1 type mismatch, expected "string[]" but got "integer"; at value /1; in value of annotation "tags"`,
+		},
+		{
+			old:     "(limitOrCount): 3",
+			new:     "(limitOrCount): three",
+			message: `Property type mismatch, expected "Limit | integer" but got "string"; in value of annotation "limitOrCount"`,
+		},
+		{
+			old:     "perMinute: 1",
+			new:     "perHour: 1",
+			message: `Property "perMinute" is required but not found in "Limit"; at value /perMinute; in value of annotation "limit"`,
+		},
+		{
+			old:     "(owners): [alice]",
+			new:     "(owners): alice",
+			message: `Property type mismatch, expected "array" but got "string"; in value of annotation "owners"`,
+		},
+		{
+			old:     "perMinute: 2",
+			new:     "perMinute: two",
+			message: `Property type mismatch, expected "Limit | integer" but got "object"; in value of annotation "limitOrCount"`,
+		},
+	}
+	for _, testcase := range testcases {
+		raml := strings.Replace(string(data), testcase.old, testcase.new, 1)
+		_, err = parser.ParseData([]byte(raml), "./test-examples")
+		require.Error(err, testcase.new)
+		require.Contains(err.Error(), testcase.message)
+		require.True(errors.As(err, new(*TypeMismatchError)) || errors.As(err, new(*RequiredPropertyError)), testcase.new)
+	}
+}
+
+func Test_ParseAnnotatedScalar(t *testing.T) {
+	assert := assert.New(t)
+	assert.NotNil(assert)
+	require := require.New(t)
+	require.NotNil(require)
+
+	parser := NewParser()
+	require.NotNil(parser)
+
+	rootdoc, err := parser.ParseFile("./test-examples/annotated-scalar.raml")
+	require.NoError(err)
+	require.Equal("Annotated Scalar API", rootdoc.Title)
+	require.Equal(false, rootdoc.ScalarAnnotations["title"]["deprecated"].Boolean)
+	require.Equal("api-team", rootdoc.ScalarAnnotations["description"]["owner"].String)
+	require.Equal("v1", rootdoc.Version)
+	require.NotContains(rootdoc.ScalarAnnotations, "version")
+	require.Equal("http://localhost/{version}", rootdoc.BaseURI)
+	require.Equal(true, rootdoc.ScalarAnnotations["baseUri"]["deprecated"].Boolean)
+	require.Equal("application/json", rootdoc.MediaType)
+	require.Equal("paging-team", rootdoc.Traits["paged"].ScalarAnnotations["usage"]["owner"].String)
+
+	if user := rootdoc.Types["User"]; assert.NotNil(user) {
+		require.Equal("User", user.DisplayName)
+		require.Contains(user.ScalarAnnotations["displayName"], "deprecated")
+		if name := user.Properties.Map()["name"]; assert.NotNil(name) {
+			require.Equal("name of user", name.Description)
+			if owner := name.ScalarAnnotations["description"]["owner"]; assert.NotNil(owner) {
+				require.Equal("user-team", owner.String)
+				require.Equal(TypeString, owner.AnnotationType.Type)
+			}
+		}
+		require.Equal("Alice", user.Example.DisplayName)
+		require.Equal("user-team", user.Example.ScalarAnnotations["displayName"]["owner"].String)
+		require.Equal("Alice", user.Example.Value.Map["name"].String)
+	}
+
+	if resource := rootdoc.Resources["/users"]; assert.NotNil(resource) {
+		require.Equal("Users", resource.DisplayName)
+		require.Equal(true, resource.ScalarAnnotations["displayName"]["deprecated"].Boolean)
+		if method := resource.Methods["get"]; assert.NotNil(method) {
+			require.Equal("list users", method.Description)
+			require.Equal("user-team", method.ScalarAnnotations["description"]["owner"].String)
+			if response := method.Responses[200]; assert.NotNil(response) {
+				require.Equal("users", response.Description)
+				require.Contains(response.ScalarAnnotations["description"], "deprecated")
+			}
+		}
+	}
+
+	data, err := ioutil.ReadFile("./test-examples/annotated-scalar.raml")
+	require.NoError(err)
+
+	raml := strings.Replace(string(data), "(owner): user-team", "(owner): [user-team]", 1)
+	_, err = parser.ParseData([]byte(raml), "./test-examples")
+	require.Error(err)
+	require.Contains(err.Error(), `in value of annotation "owner"`)
+
+	raml = strings.Replace(string(data), "value:   name of user", "vaule:   name of user", 1)
+	_, err = parser.ParseData([]byte(raml), "./test-examples")
+	require.True(ErrorTypo2.Match(err))
+	unknownErr := &UnknownFacetError{}
+	require.True(errors.As(err, &unknownErr))
+	require.Equal("value", unknownErr.Suggestions["vaule"])
+
+	raml = strings.Replace(string(data), "value:   Alice", "value:   Alice\n                valeu:   Bob", 1)
+	_, err = parser.ParseData([]byte(raml), "./test-examples")
+	require.True(ErrorTypo2.Match(err))
+	require.True(errors.As(err, &unknownErr))
+	require.Equal("value", unknownErr.Suggestions["valeu"])
+}
+
+func Test_DecodeAnnotation(t *testing.T) {
+	require := require.New(t)
+	require.NotNil(require)
+
+	parser := NewParser()
+	require.NotNil(parser)
+
+	rootdoc, err := parser.ParseData([]byte(strings.TrimSpace(`
+#%RAML 1.0
+title:
+    value:   Decode API
+    (owner): team-x
+annotationTypes:
+    owner: string
+    rateLimit:
+        properties:
+            perMinute: integer
+            burst?:    integer
+    quota: Quota | integer
+types:
+    Quota:
+        properties:
+            perDay: integer
+    User:
+        type: object
+        properties:
+            name:
+                type:    string
+                (owner): team-y
+/users:
+    (quota): 1000
+    get:
+        (rateLimit):
+            perMinute: 60
+            burst:     10
+        responses:
+            200:
+                body:
+                    application/json:
+                        type: User
+	`)), ".")
+	require.NoError(err)
+
+	type rateLimit struct {
+		PerMinute int `json:"perMinute" yaml:"perMinute"`
+		Burst     int `json:"burst" yaml:"burst"`
+	}
+
+	method := rootdoc.Resources["/users"].Methods["get"]
+	limit := rateLimit{}
+	found, err := DecodeAnnotation(method, "(rateLimit)", &limit)
+	require.NoError(err)
+	require.True(found)
+	require.Equal(rateLimit{PerMinute: 60, Burst: 10}, limit)
+
+	annotation, ok := FindAnnotation(*method, "rateLimit")
+	require.True(ok)
+	limit = rateLimit{}
+	require.NoError(annotation.DecodeYAML(&limit))
+	require.Equal(rateLimit{PerMinute: 60, Burst: 10}, limit)
+
+	found, err = DecodeAnnotation(method, "owner", &limit)
+	require.NoError(err)
+	require.False(found)
+
+	var owner string
+	found, err = DecodeAnnotation(rootdoc.ScalarAnnotations["title"], "owner", &owner)
+	require.NoError(err)
+	require.True(found)
+	require.Equal("team-x", owner)
+
+	name := rootdoc.Types["User"].Properties.Map()["name"]
+	found, err = DecodeAnnotation(name, "owner", &owner)
+	require.NoError(err)
+	require.True(found)
+	require.Equal("team-y", owner)
+
+	// members of union annotation type are resolved in the library
+	var quota int
+	found, err = DecodeAnnotation(rootdoc.Resources["/users"], "quota", &quota)
+	require.NoError(err)
+	require.True(found)
+	require.Equal(1000, quota)
+	quotaAnnotation, ok := FindAnnotation(rootdoc.Resources["/users"], "quota")
+	require.True(ok)
+	quotaAnnotation.Value = Value{Type: TypeString, String: "many"}
+	err = quotaAnnotation.Decode(&quota)
+	require.Error(err)
+	require.Contains(err.Error(), `in value of annotation "quota"`)
+
+	_, ok = FindAnnotation("not a node", "owner")
+	require.False(ok)
+
+	// value is checked against the annotation type before decoding
+	annotation.Map["perMinute"] = &Value{Type: TypeString, String: "sixty"}
+	err = annotation.Decode(&limit)
+	require.Error(err)
+	mismatchErr := &TypeMismatchError{}
+	require.True(errors.As(err, &mismatchErr))
+	require.Equal("/perMinute", mismatchErr.Pointer())
+	require.Contains(err.Error(), `in value of annotation "rateLimit"`)
+
+	annotation.Map["perMinute"] = &Value{Type: TypeInteger, Integer: 60}
+	var wrongType []string
+	err = annotation.Decode(&wrongType)
+	require.True(ErrorAnnotationDecode1.Match(err))
+}
+
+func Test_ParseExampleStrict(t *testing.T) {
+	require := require.New(t)
+	require.NotNil(require)
+
+	parser := NewParser()
+	require.NotNil(parser)
+
+	rootdoc, err := parser.ParseFile("./test-examples/example-strict.raml")
+	require.NoError(err)
+	if user := rootdoc.Types["User"]; assert.NotNil(t, user) {
+		require.True(user.Examples["alice"].Strict)
+		require.False(user.Examples["draft"].Strict)
+	}
+
+	data, err := ioutil.ReadFile("./test-examples/example-strict.raml")
+	require.NoError(err)
+
+	testcases := []struct {
+		old     string
+		new     string
+		message string
+	}{
+		{
+			old:     "age:  20",
+			new:     "age:  twenty",
+			message: `in example "alice"`,
+		},
+		{
+			old:     "                strict: false",
+			new:     "                strict: true",
+			message: `in example "draft"`,
+		},
+		{
+			old:     "example: 30",
+			new:     "example: thirty",
+			message: `expected "integer" but got "string"`,
+		},
+		{
+			old:     "example: 1\n\ntypes:",
+			new:     "example: high\n\ntypes:",
+			message: `expected "integer" but got "string"`,
+		},
+		{
+			old:     "            example: 1\n    get:",
+			new:     "            example: one\n    get:",
+			message: `expected "integer" but got "string"`,
+		},
+		{
+			old:     "example: 10",
+			new:     "example: ten",
+			message: `expected "integer" but got "string"`,
+		},
+		{
+			old:     "first: 1",
+			new:     "first: one",
+			message: `in example "first"`,
+		},
+		{
+			old:     "                            strict: false",
+			new:     "                            strict: true",
+			message: `Property "name" type mismatch`,
+		},
+	}
+	for _, testcase := range testcases {
+		raml := strings.Replace(string(data), testcase.old, testcase.new, 1)
+		require.NotEqual(string(data), raml, testcase.old)
+		_, err = parser.ParseData([]byte(raml), "./test-examples")
+		require.Error(err, testcase.new)
+		require.Contains(err.Error(), testcase.message)
+		require.NotContains(err.Error(), `in example "autoGenerated"`)
+		mismatchErr := &TypeMismatchError{}
+		require.True(errors.As(err, &mismatchErr), testcase.new)
+	}
+
+	// declared example named as the generated one is still checked
+	raml := strings.Replace(string(data), "alice:", "autoGenerated:", 1)
+	raml = strings.Replace(raml, "age:  20", "age:  twenty", 1)
+	_, err = parser.ParseData([]byte(raml), "./test-examples")
+	require.Error(err)
+	require.Contains(err.Error(), `in example "autoGenerated"`)
+
+	// invalid facet of example is not taken as the example value
+	raml = strings.Replace(string(data), "                strict: false", "                strict: never", 1)
+	_, err = parser.ParseData([]byte(raml), "./test-examples")
+	require.Error(err)
+	require.Contains(err.Error(), "never")
+}
+
+func Test_ParseIncludeExample(t *testing.T) {
+	assert := assert.New(t)
+	assert.NotNil(assert)
+	require := require.New(t)
+	require.NotNil(require)
+
+	parser := NewParser()
+	require.NotNil(parser)
+
+	rootdoc, err := parser.ParseFile("./test-examples/include-example/api.raml")
+	require.NoError(err)
+
+	exampleName := func(example *Example) string {
+		if assert.NotNil(example) && assert.Equal(TypeObject, example.Value.Type) {
+			return example.Value.Map["name"].String
+		}
+		return ""
+	}
+	if user := rootdoc.Types["User"]; assert.NotNil(user) {
+		require.Equal("Alice", exampleName(&user.Example))
+		require.Equal(TypeInteger, user.Example.Value.Map["age"].Type)
+		require.EqualValues(20, user.Example.Value.Map["age"].Integer)
+	}
+	if admin := rootdoc.Types["Admin"]; assert.NotNil(admin) {
+		require.Equal("Bob", exampleName(&admin.Example))
+	}
+	if guest := rootdoc.Types["Guest"]; assert.NotNil(guest) {
+		// JSON content of file without JSON extension
+		require.Equal("Carol", exampleName(&guest.Example))
+	}
+	if snippet := rootdoc.Types["Snippet"]; assert.NotNil(snippet) {
+		// JSON content is kept as text for string type
+		require.Equal(TypeString, snippet.Example.Value.Type)
+		require.Contains(snippet.Example.Value.String, `"name"`)
+	}
+	if member := rootdoc.Types["Member"]; assert.NotNil(member) {
+		require.Len(member.Examples, 2)
+		require.Equal("Dave", exampleName(member.Examples["dave"]))
+		require.Equal("Eve", exampleName(member.Examples["eve"]))
+		require.Equal("Eve", member.Examples["eve"].DisplayName)
+		require.False(member.Examples["eve"].Strict)
+	}
+	if staff := rootdoc.Types["Staff"]; assert.NotNil(staff) {
+		require.Equal("Alice", exampleName(staff.Examples["alice"]))
+		require.Equal("Bob", exampleName(staff.Examples["bob"]))
+		require.Equal("Bob", staff.Examples["bob"].DisplayName)
+	}
+	body := rootdoc.Resources["/users"].Methods["get"].Responses[200].Bodies["application/json"]
+	if assert.NotNil(body) {
+		require.Equal(TypeArray, body.Example.Value.Type)
+		require.Len(body.Example.Value.Array, 2)
+	}
+
+	data, err := ioutil.ReadFile("./test-examples/include-example/api.raml")
+	require.NoError(err)
+
+	raml := strings.Replace(string(data), "examples/carol.example", "examples/users.json", 1)
+	_, err = parser.ParseData([]byte(raml), "./test-examples/include-example")
+	require.Error(err)
+	require.Contains(err.Error(), `expected "User" but got "array"`)
+
+	raml = strings.Replace(string(data), "examples/members.raml", "../fragment/person.raml", 1)
+	_, err = parser.ParseData([]byte(raml), "./test-examples/include-example")
+	require.True(ErrorIncludeFile1.Match(err))
+	require.Contains(err.Error(), ErrorUnexpectedFragmentKind2.New(nil, FragmentKindNamedExample, FragmentKindDataType).Error())
+
+	// YAML content of text file is limited as other YAML
+	require.NoError(parser.Config(parserConfig.MaxYAMLAliasExpansion, int64(1000)))
+	raml = strings.Replace(string(data), "examples/carol.example", "examples/laughs.txt", 1)
+	_, err = parser.ParseData([]byte(raml), "./test-examples/include-example")
+	require.True(ErrorYAMLAliasExpansionExceeded1.Match(err))
+}
+
+func Test_ParseGenerateValue(t *testing.T) {
+	assert := assert.New(t)
+	assert.NotNil(assert)
+	require := require.New(t)
+	require.NotNil(require)
+
+	parser := NewParser()
+	require.NotNil(parser)
+
+	rootdoc, err := parser.ParseFile("./test-examples/generate-value.raml")
+	require.NoError(err)
+	if user := rootdoc.Types["User"]; assert.NotNil(user) {
+		// scalar values are not generated by default
+		require.True(user.Example.Value.Map["age"].IsEmpty())
+	}
+
+	options := []GenerateValueOption{GenerateValueOptionSeed(1)}
+	require.NoError(parser.Config(parserConfig.GenerateValueOptions, options))
+	rootdoc, err = parser.ParseFile("./test-examples/generate-value.raml")
+	require.NoError(err)
+
+	user := rootdoc.Types["User"]
+	require.NotNil(user)
+	require.Equal(user.Example.Value, user.Examples["autoGenerated"].Value)
+	value := user.Example.Value
+	require.Equal(TypeObject, value.Type)
+
+	name := value.Map["name"]
+	require.Equal(TypeString, name.Type)
+	require.True(len(name.String) >= 3 && len(name.String) <= 5, name.String)
+	require.Regexp(`^[A-Z]{3}-\d{4}$`, value.Map["code"].String)
+	require.Contains([]string{"active", "disabled"}, value.Map["status"].String)
+
+	age := value.Map["age"]
+	require.Equal(TypeInteger, age.Type)
+	require.True(age.Integer >= 18 && age.Integer <= 60, age.Integer)
+	score := value.Map["score"]
+	require.Equal(TypeNumber, score.Type)
+	require.True(score.Number >= 0 && score.Number <= 1, score.Number)
+	level := value.Map["level"]
+	require.True(level.Integer >= 10 && level.Integer <= 50, level.Integer)
+	require.Zero(level.Integer % 5)
+	require.Equal(TypeBoolean, value.Map["verified"].Type)
+
+	for name, layout := range map[string]string{
+		"birthday":   "2006-01-02",
+		"wakeUp":     "15:04:05",
+		"localTime":  "2006-01-02T15:04:05",
+		"createdAt":  time.RFC3339,
+		"modifiedAt": "Mon, 02 Jan 2006 15:04:05 GMT",
+	} {
+		_, err = time.Parse(layout, value.Map[name].String)
+		require.NoError(err, name)
+	}
+
+	tags := value.Map["tags"]
+	require.Equal(TypeArray, tags.Type)
+	require.True(len(tags.Array) >= 2 && len(tags.Array) <= 3, len(tags.Array))
+	require.NotEqual(tags.Array[0].String, tags.Array[1].String)
+
+	// declared examples are reused
+	if member := rootdoc.Types["Member"]; assert.NotNil(member) {
+		require.Equal("alice", member.Example.Value.Map["name"].String)
+	}
+	if team := rootdoc.Types["Team"]; assert.NotNil(team) {
+		require.Equal("alice", team.Example.Value.Map["leader"].Map["name"].String)
+		require.Len(team.Example.Value.Map["members"].Array, 1)
+	}
+
+	// the same seed generates the same value
+	rootdoc2, err := parser.ParseFile("./test-examples/generate-value.raml")
+	require.NoError(err)
+	require.Equal(value, rootdoc2.Types["User"].Example.Value)
+}
+
+func Test_GenerateValue(t *testing.T) {
+	assert := assert.New(t)
+	assert.NotNil(assert)
+	require := require.New(t)
+	require.NotNil(require)
+
+	parser := NewParser()
+	require.NotNil(parser)
+
+	rootdoc, err := parser.ParseFile("./test-examples/generate-value.raml")
+	require.NoError(err)
+
+	// examples generated by parsing are not reused
+	user := rootdoc.Types["User"]
+	require.NotNil(user)
+	value, err := GenerateValue(*user, rootdoc.Library)
+	require.NoError(err)
+	require.Equal(TypeInteger, value.Map["age"].Type)
+	require.NoError(CheckValueAPIType(*user, value))
+
+	member := rootdoc.Types["Member"]
+	require.NotNil(member)
+	value, err = GenerateValue(*member, rootdoc.Library, GenerateValueOptionSeed(1))
+	require.NoError(err)
+	require.Equal("alice", value.Map["name"].String)
+
+	value, err = GenerateValue(*member, rootdoc.Library, GenerateValueOptionSeed(1), GenerateValueOptionIgnoreExamples(true))
+	require.NoError(err)
+	require.NotEqual("alice", value.Map["name"].String)
+	require.NoError(CheckValueAPIType(*member, value))
+
+	union := NewAPIType()
+	union.setType("integer | boolean")
+	value, err = GenerateValue(*union, rootdoc.Library)
+	require.NoError(err)
+	require.Contains([]string{TypeInteger, TypeBoolean}, value.Type)
+
+	int8Type := NewAPIType()
+	int8Type.setType(TypeInteger)
+	int8Type.Format = "int8"
+	minimum := float64(120)
+	int8Type.Minimum = &minimum
+	value, err = GenerateValue(*int8Type, rootdoc.Library)
+	require.NoError(err)
+	require.True(value.Integer >= 120 && value.Integer <= 127, value.Integer)
+
+	pattern := NewAPIType()
+	pattern.setType(TypeString)
+	pattern.Pattern = "["
+	_, err = GenerateValue(*pattern, rootdoc.Library)
+	require.True(ErrorGenerateValuePattern1.Match(err))
+}
+
 func Test_GobEncodeDecode(t *testing.T) {
 	require := require.New(t)
 	require.NotNil(require)
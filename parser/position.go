@@ -0,0 +1,377 @@
+package parser
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/tsaikd/KDGoLib/errutil"
+)
+
+// Position source position of a parsed node, line and column start from 1
+type Position struct {
+	// canonical location of RAML file, empty if parsed from data
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+}
+
+// IsZero return true if position is unknown
+func (t Position) IsZero() bool {
+	return t.Line < 1
+}
+
+func (t Position) String() string {
+	if t.File == "" {
+		return fmt.Sprintf("%d:%d", t.Line, t.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", t.File, t.Line, t.Column)
+}
+
+// positionError the oldest parent of error chain, which is the position of
+// the node causing error
+type positionError struct {
+	errutil.ErrorObject
+	position Position
+}
+
+// ErrorPosition return the source position attached to err
+func ErrorPosition(err error) (position Position, ok bool) {
+	if err == nil {
+		return
+	}
+	_ = errutil.WalkErrors(errutil.NewErrors(err), func(errcomp errutil.ErrorObject) (stop bool, walkerr error) {
+		if posErr, isPosErr := errcomp.(*positionError); isPosErr {
+			position, ok = posErr.position, true
+			return true, nil
+		}
+		return false, nil
+	})
+	return
+}
+
+// withPosition return err with position attached if err has no position
+func withPosition(err error, position Position) error {
+	if err == nil || position.IsZero() {
+		return err
+	}
+	if _, ok := ErrorPosition(err); ok {
+		return err
+	}
+	errobj := errutil.NewErrors(err)
+	posErr := &positionError{
+		ErrorObject: ErrorSourcePosition1.New(nil, position),
+		position:    position,
+	}
+	if errutil.AddParent(errobj, posErr) != nil {
+		return err
+	}
+	return errobj
+}
+
+// positionSite node in document which is loaded from node of other document,
+// e.g. !include or project files
+type positionSite struct {
+	location string
+	pointer  string
+}
+
+// positionIndex source positions of nodes in a document by JSON pointer
+type positionIndex struct {
+	positions map[string]Position
+	sites     map[string]positionSite
+}
+
+// indexPositions record positions of nodes in data of the document at
+// location, the document is indexed only once
+func (t *fileLoader) indexPositions(location string, data []byte) {
+	index := t.positionIndex(location)
+	if index.positions == nil {
+		index.positions = indexYAMLPositions(data)
+	}
+}
+
+// addPositionSite record node at pointer of the document at location is
+// loaded from node at sitePointer of the document at siteLocation
+func (t *fileLoader) addPositionSite(location string, pointer string, siteLocation string, sitePointer string) {
+	t.positionIndex(location).sites[pointer] = positionSite{
+		location: siteLocation,
+		pointer:  sitePointer,
+	}
+}
+
+func (t *fileLoader) positionIndex(location string) *positionIndex {
+	if t.positions == nil {
+		t.positions = map[string]*positionIndex{}
+	}
+	index, ok := t.positions[location]
+	if !ok {
+		index = &positionIndex{sites: map[string]positionSite{}}
+		t.positions[location] = index
+	}
+	return index
+}
+
+// position return the position of node at pointer of the document at
+// location, position of the nearest ancestor is returned if not found
+func (t fileLoader) position(location string, pointer string) Position {
+	// limit loading sites to avoid loop
+	for i := 0; i < 64; i++ {
+		index := t.positions[location]
+		if index == nil {
+			return Position{}
+		}
+
+		prefix, found := "", false
+		for sitePointer := range index.sites {
+			if (pointer == sitePointer || strings.HasPrefix(pointer, sitePointer+"/")) && len(sitePointer) >= len(prefix) {
+				prefix, found = sitePointer, true
+			}
+		}
+		if found {
+			site := index.sites[prefix]
+			location, pointer = site.location, site.pointer+pointer[len(prefix):]
+			continue
+		}
+
+		for {
+			if position, ok := index.positions[pointer]; ok {
+				if !strings.HasSuffix(location, string(filepath.Separator)) {
+					position.File = location
+				}
+				return position
+			}
+			if pointer == "" {
+				return Position{}
+			}
+			pointer = pointer[:strings.LastIndex(pointer, "/")]
+		}
+	}
+	return Position{}
+}
+
+// indexYAMLPositions return positions of nodes in YAML data by JSON pointer,
+// only block style nodes are indexed, nodes in flow style or multi-line
+// scalars are located by the nearest indexed ancestor
+func indexYAMLPositions(data []byte) map[string]Position {
+	type level struct {
+		indent  int
+		pointer string
+		// key without value in the same line, children are expected
+		open bool
+		// level of sequence item
+		item bool
+		// number of sequence items
+		items int
+	}
+
+	positions := map[string]Position{"": {Line: 1, Column: 1}}
+	stack := []*level{{indent: -1, open: true}}
+	// skip lines indented more than skipIndent, e.g. block scalar
+	skipIndent := -1
+
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		content := strings.TrimLeft(line, " ")
+		indent := len(line) - len(content)
+		if skipIndent >= 0 {
+			if indent > skipIndent || content == "" {
+				continue
+			}
+			skipIndent = -1
+		}
+		if content == "" || strings.HasPrefix(content, "#") || content == "---" || content == "..." {
+			continue
+		}
+
+		for content != "" {
+			isItem := content == "-" || strings.HasPrefix(content, "- ")
+			for len(stack) > 1 {
+				top := stack[len(stack)-1]
+				if top.indent < indent || (isItem && top.indent == indent && top.open && !top.item) {
+					break
+				}
+				stack = stack[:len(stack)-1]
+			}
+			parent := stack[len(stack)-1]
+
+			if isItem {
+				pointer := parent.pointer + "/" + strconv.Itoa(parent.items)
+				parent.items++
+				positions[pointer] = Position{Line: i + 1, Column: indent + 1}
+				rest := strings.TrimLeft(strings.TrimPrefix(content, "-"), " ")
+				stack = append(stack, &level{indent: indent, pointer: pointer, item: true, open: rest == ""})
+				indent += len(content) - len(rest)
+				content = rest
+				continue
+			}
+
+			key, rest, ok := splitYAMLKey(content)
+			if !ok {
+				break
+			}
+			pointer := parent.pointer + "/" + escapeJSONPointer(key)
+			positions[pointer] = Position{Line: i + 1, Column: indent + 1}
+			open := rest == "" ||
+				(strings.HasPrefix(rest, "&") || strings.HasPrefix(rest, "!")) && !strings.Contains(rest, " ")
+			stack = append(stack, &level{indent: indent, pointer: pointer, open: open})
+
+			switch {
+			case strings.HasPrefix(rest, "|"), strings.HasPrefix(rest, ">"):
+				skipIndent = indent
+			case strings.HasPrefix(rest, "{"), strings.HasPrefix(rest, "["):
+				if strings.Count(rest, "{")+strings.Count(rest, "[") > strings.Count(rest, "}")+strings.Count(rest, "]") {
+					// multi-line flow collection
+					skipIndent = indent
+				}
+			}
+			break
+		}
+	}
+	return positions
+}
+
+// splitYAMLKey split YAML mapping line into key and value,
+// return false if content is not a mapping
+func splitYAMLKey(content string) (key string, rest string, ok bool) {
+	switch {
+	case strings.HasPrefix(content, `"`):
+		end := 1
+		for ; end < len(content); end++ {
+			if content[end] == '\\' {
+				end++
+			} else if content[end] == '"' {
+				break
+			}
+		}
+		if end >= len(content) {
+			return "", "", false
+		}
+		var err error
+		if key, err = strconv.Unquote(content[:end+1]); err != nil {
+			return "", "", false
+		}
+		rest = content[end+1:]
+	case strings.HasPrefix(content, "'"):
+		end := 1
+		for ; end < len(content); end++ {
+			if content[end] == '\'' {
+				if end+1 < len(content) && content[end+1] == '\'' {
+					end++
+					continue
+				}
+				break
+			}
+		}
+		if end >= len(content) {
+			return "", "", false
+		}
+		key = strings.Replace(content[1:end], "''", "'", -1)
+		rest = content[end+1:]
+	case strings.HasPrefix(content, "{"), strings.HasPrefix(content, "["), strings.HasPrefix(content, "? "):
+		return "", "", false
+	default:
+		idx := strings.Index(content, ": ")
+		if strings.HasSuffix(content, ":") && (idx < 0 || idx == len(content)-1) {
+			idx = len(content) - 1
+		}
+		if idx < 0 {
+			return "", "", false
+		}
+		key = strings.TrimSpace(content[:idx])
+		rest = content[idx:]
+	}
+
+	if !strings.HasPrefix(rest, ":") {
+		return "", "", false
+	}
+	rest = strings.TrimSpace(rest[1:])
+	if strings.HasPrefix(rest, "#") {
+		rest = ""
+	}
+	return key, rest, true
+}
+
+var reflectTypePosition = reflect.TypeOf(Position{})
+var reflectTypeProperties = reflect.TypeOf(Properties{})
+
+// fillPositions set Position of nodes in v, which is unmarshaled from the
+// node at pointer of the document at location, the path of nodes are
+// derived from yaml tags of struct fields
+func fillPositions(loader *fileLoader, v interface{}, location string, pointer string) {
+	walkPositions(loader, reflect.ValueOf(v), location, pointer)
+}
+
+func walkPositions(loader *fileLoader, val reflect.Value, location string, pointer string) {
+	switch val.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !val.IsNil() {
+			walkPositions(loader, val.Elem(), location, pointer)
+		}
+	case reflect.Struct:
+		switch val.Type() {
+		case reflectTypeValue, reflectTypePosition:
+			return
+		case reflectTypeProperties:
+			for _, property := range val.Interface().(Properties).Slice() {
+				walkPositions(loader, reflect.ValueOf(property), location, pointer+"/"+escapeJSONPointer(property.Name))
+			}
+			return
+		}
+		for i, n := 0, val.NumField(); i < n; i++ {
+			field := val.Type().Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			if field.Name == "Position" && field.Type == reflectTypePosition {
+				if val.Field(i).CanSet() {
+					val.Field(i).Set(reflect.ValueOf(loader.position(location, pointer)))
+				}
+				continue
+			}
+
+			tag := field.Tag.Get("yaml")
+			name := strings.Split(tag, ",")[0]
+			childPointer := pointer
+			switch {
+			case name == "-":
+				continue
+			case name != "":
+				childPointer = pointer + "/" + escapeJSONPointer(name)
+			case field.Anonymous, strings.Contains(tag, ",inline"), strings.Contains(tag, ",regexp:"):
+				// keys are in the same map
+			default:
+				childPointer = pointer + "/" + escapeJSONPointer(strings.ToLower(field.Name))
+			}
+			walkPositions(loader, val.Field(i), location, childPointer)
+		}
+	case reflect.Map:
+		for _, key := range val.MapKeys() {
+			walkPositions(loader, val.MapIndex(key), location, pointer+"/"+escapeJSONPointer(fmt.Sprint(key.Interface())))
+		}
+	case reflect.Slice:
+		for i, n := 0, val.Len(); i < n; i++ {
+			walkPositions(loader, val.Index(i), location, pointer+"/"+strconv.Itoa(i))
+		}
+	}
+}
+
+// nodePosition return the Position field of struct val
+func nodePosition(val reflect.Value) Position {
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return Position{}
+		}
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return Position{}
+	}
+	field := val.FieldByName("Position")
+	if !field.IsValid() || field.Type() != reflectTypePosition {
+		return Position{}
+	}
+	return field.Interface().(Position)
+}
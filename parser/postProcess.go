@@ -122,6 +122,16 @@ func checkTypoErrorExec(v interface{}, conf PostProcessConfig) (err error) {
 	return v.(checkTypoError).checkTypoError()
 }
 
+type checkReference interface {
+	checkReference(conf PostProcessConfig) (err error)
+}
+
+var checkReferenceRef = reflect.TypeOf((*checkReference)(nil)).Elem()
+
+func checkReferenceExec(v interface{}, conf PostProcessConfig) (err error) {
+	return v.(checkReference).checkReference(conf)
+}
+
 type checkUnusedAnnotation interface {
 	checkUnusedAnnotation(conf PostProcessConfig) (err error)
 }
@@ -162,6 +172,36 @@ func afterCheckUnusedTraitExec(v interface{}, conf PostProcessConfig) (err error
 	return v.(afterCheckUnusedTrait).afterCheckUnusedTrait(conf)
 }
 
+type checkUnusedType interface {
+	checkUnusedType(conf PostProcessConfig) (err error)
+}
+
+var checkUnusedTypeRef = reflect.TypeOf((*checkUnusedType)(nil)).Elem()
+
+func checkUnusedTypeExec(v interface{}, conf PostProcessConfig) (err error) {
+	return v.(checkUnusedType).checkUnusedType(conf)
+}
+
+type afterCheckUnusedType interface {
+	afterCheckUnusedType(conf PostProcessConfig) (err error)
+}
+
+var afterCheckUnusedTypeRef = reflect.TypeOf((*afterCheckUnusedType)(nil)).Elem()
+
+func afterCheckUnusedTypeExec(v interface{}, conf PostProcessConfig) (err error) {
+	return v.(afterCheckUnusedType).afterCheckUnusedType(conf)
+}
+
+type afterCheckUnusedLibrary interface {
+	afterCheckUnusedLibrary(conf PostProcessConfig) (err error)
+}
+
+var afterCheckUnusedLibraryRef = reflect.TypeOf((*afterCheckUnusedLibrary)(nil)).Elem()
+
+func afterCheckUnusedLibraryExec(v interface{}, conf PostProcessConfig) (err error) {
+	return v.(afterCheckUnusedLibrary).afterCheckUnusedLibrary(conf)
+}
+
 type checkAnnotation interface {
 	checkAnnotation(conf PostProcessConfig) (err error)
 }
@@ -197,16 +237,20 @@ var postProcessInfoMap = map[reflect.Type]postProcessFunc{
 	fillURIParamsRef:              fillURIParamsExec,
 	fillExampleRef:                fillExampleExec,
 	checkTypoErrorRef:             checkTypoErrorExec,
+	checkReferenceRef:             checkReferenceExec,
 	checkUnusedAnnotationRef:      checkUnusedAnnotationExec,
 	afterCheckUnusedAnnotationRef: afterCheckUnusedAnnotationExec,
 	checkUnusedTraitRef:           checkUnusedTraitExec,
 	afterCheckUnusedTraitRef:      afterCheckUnusedTraitExec,
+	checkUnusedTypeRef:            checkUnusedTypeExec,
+	afterCheckUnusedTypeRef:       afterCheckUnusedTypeExec,
+	afterCheckUnusedLibraryRef:    afterCheckUnusedLibraryExec,
 	checkAnnotationRef:            checkAnnotationExec,
 	checkExampleRef:               checkExampleExec,
 }
 
 func postProcess(v interface{}, conf PostProcessConfig) (err error) {
-	implements := []reflect.Type{
+	fillImplements := []reflect.Type{
 		loadExternalUseRef,
 		fixRequiredBySyntaxRef,
 		fixDefaultMediaTypeRef,
@@ -218,27 +262,101 @@ func postProcess(v interface{}, conf PostProcessConfig) (err error) {
 		fillTraitRef,
 		fillURIParamsRef,
 		fillExampleRef,
+	}
+	// check stages are independent
+	checkImplements := []reflect.Type{
 		checkTypoErrorRef,
+		checkReferenceRef,
 		checkUnusedAnnotationRef,
 		afterCheckUnusedAnnotationRef,
 		checkUnusedTraitRef,
 		afterCheckUnusedTraitRef,
+		checkUnusedTypeRef,
+		afterCheckUnusedTypeRef,
+		// library usage depends on usage of all members
+		afterCheckUnusedLibraryRef,
 		checkAnnotationRef,
 		checkExampleRef,
 	}
-	for _, implement := range implements {
-		if err = postProcessImplement(reflect.ValueOf(v), implement, conf); err != nil {
-			return
+
+	// nodes are incomplete if fill stages failed, stop before next stage,
+	// or skip the failed nodes in next stages if collecting diagnostics
+	collector := diagnosticCollectorOf(conf.Parser())
+	failed := postProcessFailedNodes{}
+	for _, implement := range fillImplements {
+		if implementErr := postProcessImplementAt(reflect.ValueOf(v), implement, conf, Position{}, failed); implementErr != nil {
+			if collector == nil {
+				return implementErr
+			}
+			if err == nil {
+				err = implementErr
+			}
+		}
+	}
+	for _, implement := range checkImplements {
+		// nodes failed in check stage are still complete
+		if implementErr := postProcessImplementAt(reflect.ValueOf(v), implement, conf, Position{}, failed.clone()); implementErr != nil {
+			if collector == nil {
+				return implementErr
+			}
+			if err == nil {
+				err = implementErr
+			}
 		}
 	}
 	return
 }
 
+// postProcessFailedNodes nodes failed in post process stages, identified by
+// type and address because embedded struct shares address with parent
+type postProcessFailedNodes map[postProcessNode]bool
+
+type postProcessNode struct {
+	typ  reflect.Type
+	addr uintptr
+}
+
+func (t postProcessFailedNodes) clone() postProcessFailedNodes {
+	result := postProcessFailedNodes{}
+	for node := range t {
+		result[node] = true
+	}
+	return result
+}
+
+// nodeOf return the identity of val, ok is false if val has no address
+func (t postProcessFailedNodes) nodeOf(val reflect.Value) (node postProcessNode, ok bool) {
+	switch {
+	case val.Kind() == reflect.Ptr:
+		return postProcessNode{typ: val.Type().Elem(), addr: val.Pointer()}, true
+	case val.CanAddr():
+		return postProcessNode{typ: val.Type(), addr: val.UnsafeAddr()}, true
+	}
+	return
+}
+
 var reflectTypeValue = reflect.TypeOf(Value{})
 var reflectTypeValuePtr = reflect.TypeOf(&Value{})
 var reflectTypeLibrary = reflect.TypeOf(&Library{})
 
 func postProcessImplement(val reflect.Value, implement reflect.Type, conf PostProcessConfig) (err error) {
+	return postProcessImplementAt(val, implement, conf, Position{}, postProcessFailedNodes{})
+}
+
+// postProcessImplementAt run implement of val recursively, errors are
+// attached with the position of the nearest node which has position,
+// all nodes are walked if collecting diagnostics and the first error is returned,
+// nodes in failed are skipped and nodes failed to run implement are added
+func postProcessImplementAt(val reflect.Value, implement reflect.Type, conf PostProcessConfig, position Position, failed postProcessFailedNodes) (err error) {
+	if val.Kind() == reflect.Ptr && val.IsNil() {
+		// nil pointer might implement by promoted methods of embedded field
+		return nil
+	}
+	node, hasNode := failed.nodeOf(val)
+	if hasNode && failed[node] {
+		return nil
+	}
+
 	switch val.Type() {
 	case reflectTypeValue, reflectTypeValuePtr:
 		// no need to post process Value
@@ -250,20 +368,40 @@ func postProcessImplement(val reflect.Value, implement reflect.Type, conf PostPr
 			val.Interface().(*Library),
 			conf.AnnotationUsage(),
 			conf.TraitUsage(),
+			conf.TypeUsage(),
 		)
 	}
 
+	if nodePos := nodePosition(val); !nodePos.IsZero() {
+		position = nodePos
+	}
+
+	collector := diagnosticCollectorOf(conf.Parser())
+	// return true if walking should stop
+	report := func(childErr error) bool {
+		if err == nil {
+			err = childErr
+		}
+		return collector == nil
+	}
+
 	if v := queryPostProcessImplement(val, implement); v != nil {
-		if err = postProcessInfoMap[implement](v, conf); err != nil {
-			return
+		if execErr := postProcessInfoMap[implement](v, conf); execErr != nil {
+			execErr = withPosition(execErr, position)
+			if hasNode {
+				failed[node] = true
+			}
+			if collector != nil {
+				collector.Add(newDiagnostic(SeverityError, execErr))
+			}
+			if report(execErr) {
+				return
+			}
 		}
 	}
 
 	kind := val.Kind()
 	if kind == reflect.Ptr {
-		if val.IsNil() {
-			return
-		}
 		kind = val.Elem().Kind()
 		val = val.Elem()
 	}
@@ -271,19 +409,24 @@ func postProcessImplement(val reflect.Value, implement reflect.Type, conf PostPr
 	switch kind {
 	case reflect.Struct:
 		for i, n := 0, val.NumField(); i < n; i++ {
-			if err = postProcessImplement(val.Field(i), implement, conf); err != nil {
+			if val.Type().Field(i).PkgPath != "" {
+				// unexported fields can not run implement,
+				// and might be a lazy reference to a recursive type
+				continue
+			}
+			if childErr := postProcessImplementAt(val.Field(i), implement, conf, position, failed); childErr != nil && report(childErr) {
 				return
 			}
 		}
 	case reflect.Slice:
 		for i, n := 0, val.Len(); i < n; i++ {
-			if err = postProcessImplement(val.Index(i), implement, conf); err != nil {
+			if childErr := postProcessImplementAt(val.Index(i), implement, conf, position, failed); childErr != nil && report(childErr) {
 				return
 			}
 		}
 	case reflect.Map:
 		for _, key := range val.MapKeys() {
-			if err = postProcessImplement(val.MapIndex(key), implement, conf); err != nil {
+			if childErr := postProcessImplementAt(val.MapIndex(key), implement, conf, position, failed); childErr != nil && report(childErr) {
 				return
 			}
 		}
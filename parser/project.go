@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// projectSections top-level nodes which are merged by declarations,
+// other top-level nodes should be declared in only one project file
+var projectSections = map[string]bool{
+	"uses":            true,
+	"types":           true,
+	"schemas":         true,
+	"traits":          true,
+	"resourceTypes":   true,
+	"annotationTypes": true,
+	"securitySchemes": true,
+}
+
+// loadProject parse each *.raml file in directory recursively and merge the
+// top-level nodes into a RAML document, declaring the same node in different
+// files is a conflict, errors of each file are reported with the file name,
+// fragment files are skipped because they are loaded by uses or !include
+func (t parserImpl) loadProject(dirPath string) (data []byte, err error) {
+	loader := t.fileLoader()
+	names, err := loader.listRAMLFiles(dirPath)
+	if err != nil {
+		return
+	}
+
+	location := directoryLocation(dirPath)
+	header := ""
+	merged := &yamlNode{Value: yamlMap{}}
+	// the file declaring each node, by JSON pointer
+	sources := map[string]string{}
+	for _, name := range names {
+		fileData, fileLocation, err := loader.readFile("", name)
+		if err != nil {
+			return nil, ErrorLoadProjectFile1.New(err, name)
+		}
+		if kind, err := ParseFragmentHeader(fileData); err == nil && kind != FragmentKindRootDocument {
+			// fragments are loaded by uses or !include
+			continue
+		}
+		if header == "" && bytes.HasPrefix(fileData, []byte("#%RAML")) {
+			// keep RAML header for checking version
+			header = strings.SplitN(string(fileData), "\n", 2)[0]
+		}
+
+		tree := &yamlNode{}
+		if err = t.unmarshalYAML(fileData, fileLocation, tree); err != nil {
+			return nil, ErrorLoadProjectFile1.New(err, fileLocation)
+		}
+		if tree.Value == nil {
+			// empty file
+			continue
+		}
+		if _, ok := tree.Value.(yamlMap); !ok {
+			return nil, ErrorLoadProjectFile1.New(ErrorInvalidProjectFile.New(nil), fileLocation)
+		}
+
+		rebaseUsesPaths(tree, fileLocation, location)
+		if err = mergeProjectNode(merged, tree, fileLocation, sources); err != nil {
+			return nil, err
+		}
+	}
+
+	// positions of merged nodes are in the declaring files
+	for pointer, file := range sources {
+		loader.addPositionSite(location, pointer, file, pointer)
+	}
+
+	return append([]byte(header+"\n"), merged.marshalFlow()...), nil
+}
+
+// mergeProjectNode merge top-level nodes of project file into merged
+func mergeProjectNode(merged *yamlNode, tree *yamlNode, file string, sources map[string]string) (err error) {
+	mergedMap := merged.Value.(yamlMap)
+	for _, item := range tree.Value.(yamlMap) {
+		key := fmt.Sprint(item.Key)
+		pointer := "/" + escapeJSONPointer(key)
+
+		node := merged.get(key)
+		if node == nil {
+			mergedMap = append(mergedMap, item)
+			sources[pointer] = file
+			if declarations, ok := item.Value.Value.(yamlMap); ok && projectSections[key] {
+				for _, declaration := range declarations {
+					sources[pointer+"/"+escapeJSONPointer(fmt.Sprint(declaration.Key))] = file
+				}
+			}
+			continue
+		}
+
+		nodeMap, isNodeMap := node.Value.(yamlMap)
+		if node.Value == nil {
+			nodeMap, isNodeMap = yamlMap{}, true
+		}
+		declarations, isDeclarations := item.Value.Value.(yamlMap)
+		if !projectSections[key] || !isNodeMap || !isDeclarations {
+			return ErrorProjectConflict3.New(nil, pointer, sources[pointer], file)
+		}
+		for _, declaration := range declarations {
+			name := fmt.Sprint(declaration.Key)
+			childPointer := pointer + "/" + escapeJSONPointer(name)
+			if node.get(name) != nil {
+				return ErrorProjectConflict3.New(nil, childPointer, sources[childPointer], file)
+			}
+			nodeMap = append(nodeMap, declaration)
+			sources[childPointer] = file
+		}
+		node.Value = nodeMap
+	}
+	merged.Value = mergedMap
+	return nil
+}
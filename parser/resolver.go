@@ -0,0 +1,115 @@
+package parser
+
+import (
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Resolver resolve documents referenced by uses, !include and extends,
+// used to load RAML from custom sources, e.g. package registry or VCS
+type Resolver interface {
+	// Resolve return the data and the canonical location of reference,
+	// location is the canonical location of the including document, or a
+	// directory with trailing separator if the document is not loaded by
+	// Resolver, e.g. "specs/" for Parser.ParseData(data, "specs").
+	// Canonical location is passed as location when resolving references
+	// in the resolved document, and passed as reference with empty location
+	// to load the same document again, e.g. binary example files.
+	Resolve(location string, reference string) (data []byte, canonical string, err error)
+}
+
+// NewFileResolver return Resolver which reads OS file system, reference is
+// relative to the directory of location, file access is restricted in the
+// sandbox directory if sandboxDirectory is not empty
+func NewFileResolver(sandboxDirectory string) Resolver {
+	return fileResolver{sandboxDirectory: sandboxDirectory}
+}
+
+// NewFSResolver return Resolver which reads fsys, reference is relative to
+// the directory of location
+func NewFSResolver(fsys fs.FS) Resolver {
+	return fsResolver{fsys: fsys}
+}
+
+// directoryLocation return location of document in workdir which is not
+// loaded by Resolver
+func directoryLocation(workdir string) string {
+	if workdir == "" || strings.HasSuffix(workdir, string(filepath.Separator)) {
+		return workdir
+	}
+	return workdir + string(filepath.Separator)
+}
+
+type fileResolver struct {
+	sandboxDirectory string
+}
+
+func (t fileResolver) Resolve(location string, reference string) (data []byte, canonical string, err error) {
+	canonical = reference
+	if !filepath.IsAbs(reference) {
+		canonical = filepath.Join(filepath.Dir(location), reference)
+	}
+	if err = t.checkSandbox(canonical); err != nil {
+		return
+	}
+	data, err = ioutil.ReadFile(canonical)
+	return
+}
+
+// checkSandbox return error if path is outside of the sandbox directory,
+// symbolic links are resolved before checking
+func (t fileResolver) checkSandbox(path string) (err error) {
+	if t.sandboxDirectory == "" {
+		return nil
+	}
+
+	sandbox, err := resolvePath(t.sandboxDirectory)
+	if err != nil {
+		return
+	}
+	target, err := resolvePath(path)
+	if err != nil {
+		return
+	}
+
+	rel, err := filepath.Rel(sandbox, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ErrorFileOutsideSandbox1.New(err, path)
+	}
+	return nil
+}
+
+// resolvePath return absolute path with symbolic links resolved,
+// the path is returned without resolving if it does not exist
+func resolvePath(path string) (result string, err error) {
+	if result, err = filepath.Abs(path); err != nil {
+		return
+	}
+	resolved, err := filepath.EvalSymlinks(result)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return result, nil
+		}
+		return
+	}
+	return resolved, nil
+}
+
+type fsResolver struct {
+	fsys fs.FS
+}
+
+func (t fsResolver) Resolve(location string, reference string) (data []byte, canonical string, err error) {
+	canonical = path.Join(path.Dir(filepath.ToSlash(location)), filepath.ToSlash(reference))
+	data, err = fs.ReadFile(t.fsys, canonical)
+	return
+}
+
+// fsPath convert OS file path to the path used by fs.FS
+func fsPath(name string) string {
+	return path.Clean(filepath.ToSlash(name))
+}
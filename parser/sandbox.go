@@ -0,0 +1,215 @@
+package parser
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/tsaikd/KDGoLib/futil"
+	"github.com/tsaikd/go-raml-parser/parser/parserConfig"
+	"github.com/tsaikd/yaml"
+)
+
+// fileLoader read files for parsing by Resolver, file access is limited
+// by parser config
+type fileLoader struct {
+	resolver Resolver
+
+	librarySearchPaths    []string
+	maxIncludeDepth       int64
+	maxIncludeBytes       int64
+	maxYAMLAliasExpansion int64
+
+	// total bytes of included files in parsing
+	includedBytes int64
+	// files read in parsing, used to invalidate cache
+	dependencies map[string]cacheDependency
+	// source positions of nodes by canonical location of file
+	positions map[string]*positionIndex
+}
+
+func newFileLoader(parser Parser) *fileLoader {
+	sandboxDirectory := ""
+	if value, err := parser.Get(parserConfig.SandboxDirectory); err == nil {
+		sandboxDirectory, _ = value.(string)
+	}
+	loader := &fileLoader{resolver: NewFileResolver(sandboxDirectory)}
+	if value, err := parser.Get(parserConfig.Resolver); err == nil {
+		if resolver, ok := value.(Resolver); ok && resolver != nil {
+			loader.resolver = resolver
+		}
+	}
+	if value, err := parser.Get(parserConfig.LibrarySearchPaths); err == nil {
+		loader.librarySearchPaths, _ = value.([]string)
+	}
+	if value, err := parser.Get(parserConfig.MaxIncludeDepth); err == nil {
+		loader.maxIncludeDepth, _ = value.(int64)
+	}
+	if value, err := parser.Get(parserConfig.MaxIncludeBytes); err == nil {
+		loader.maxIncludeBytes, _ = value.(int64)
+	}
+	if value, err := parser.Get(parserConfig.MaxYAMLAliasExpansion); err == nil {
+		loader.maxYAMLAliasExpansion, _ = value.(int64)
+	}
+	return loader
+}
+
+// fileLoaderOf return the fileLoader of current parsing
+func fileLoaderOf(parser Parser) *fileLoader {
+	if impl, ok := parser.(*parserImpl); ok {
+		return impl.fileLoader()
+	}
+	return newFileLoader(parser)
+}
+
+// readFile read document referenced from location without counting
+// the included bytes, return the data and the canonical location
+func (t *fileLoader) readFile(location string, reference string) (data []byte, canonical string, err error) {
+	if data, canonical, err = t.resolver.Resolve(location, reference); err != nil {
+		return
+	}
+	t.addDependency(cacheDependency{Location: canonical, Hash: sha512.Sum512(data)})
+	t.indexPositions(canonical, data)
+	return
+}
+
+// isDir return true if path is a directory
+func (t fileLoader) isDir(path string) bool {
+	if resolver, ok := t.resolver.(fsResolver); ok {
+		info, err := fs.Stat(resolver.fsys, fsPath(path))
+		return err == nil && info.IsDir()
+	}
+	return futil.IsDir(path)
+}
+
+// glob return the names of files matching pattern
+func (t fileLoader) glob(pattern string) (matches []string, err error) {
+	if resolver, ok := t.resolver.(fsResolver); ok {
+		return fs.Glob(resolver.fsys, fsPath(pattern))
+	}
+	return filepath.Glob(pattern)
+}
+
+// listRAMLFiles return the sorted names of *.raml files in directory
+// recursively, the file list is recorded to invalidate cache
+func (t *fileLoader) listRAMLFiles(dirPath string) (names []string, err error) {
+	if names, err = t.findRAMLFiles(dirPath); err != nil {
+		return
+	}
+	t.addDependency(cacheDependency{Location: dirPath, Directory: true, Hash: hashFileNames(names)})
+	return
+}
+
+// findRAMLFiles return the sorted names of *.raml files in directory recursively
+func (t fileLoader) findRAMLFiles(dirPath string) (names []string, err error) {
+	if resolver, ok := t.resolver.(fsResolver); ok {
+		err = fs.WalkDir(resolver.fsys, fsPath(dirPath), func(name string, entry fs.DirEntry, err error) error {
+			if err == nil && !entry.IsDir() && path.Ext(name) == ".raml" {
+				names = append(names, name)
+			}
+			return err
+		})
+	} else {
+		err = filepath.Walk(dirPath, func(name string, info os.FileInfo, err error) error {
+			if err == nil && !info.IsDir() && filepath.Ext(name) == ".raml" {
+				names = append(names, name)
+			}
+			return err
+		})
+	}
+	sort.Strings(names)
+	return
+}
+
+// includeFile read document included by RAML, the size is counted to
+// the total included bytes limit
+func (t *fileLoader) includeFile(location string, reference string) (data []byte, canonical string, err error) {
+	if data, canonical, err = t.readFile(location, reference); err != nil {
+		return
+	}
+	t.includedBytes += int64(len(data))
+	if t.maxIncludeBytes > 0 && t.includedBytes > t.maxIncludeBytes {
+		return nil, canonical, ErrorIncludeBytesExceeded1.New(nil, t.maxIncludeBytes)
+	}
+	return
+}
+
+// includeLibrary read library file used by RAML, the library search paths
+// are consulted in order if relative reference is not found next to the
+// document at location
+func (t *fileLoader) includeLibrary(location string, reference string) (data []byte, canonical string, err error) {
+	data, canonical, err = t.includeFile(location, reference)
+	if err == nil || !errors.Is(err, fs.ErrNotExist) || filepath.IsAbs(reference) {
+		return
+	}
+	for _, dir := range t.librarySearchPaths {
+		found, foundCanonical, errSearch := t.includeFile(directoryLocation(dir), reference)
+		if errSearch == nil || !errors.Is(errSearch, fs.ErrNotExist) {
+			return found, foundCanonical, errSearch
+		}
+	}
+	return
+}
+
+// checkIncludeDepth return error if depth of include or uses exceeds limit
+func (t fileLoader) checkIncludeDepth(depth int) (err error) {
+	if t.maxIncludeDepth > 0 && int64(depth) > t.maxIncludeDepth {
+		return ErrorIncludeDepthExceeded1.New(nil, t.maxIncludeDepth)
+	}
+	return nil
+}
+
+// unmarshalYAML unmarshal YAML data after checking alias expansion limit
+func (t fileLoader) unmarshalYAML(data []byte, v interface{}) (err error) {
+	if t.maxYAMLAliasExpansion > 0 && bytes.Contains(data, []byte("&")) {
+		if err = countYAMLNodes(data, t.maxYAMLAliasExpansion); err != nil {
+			return
+		}
+	}
+	return yaml.Unmarshal(data, v)
+}
+
+// yaml decoder create elements without state, so the counting state is
+// shared by all yamlNodeCounter and protected by the lock
+var yamlNodeCounterLock sync.Mutex
+var yamlNodeCounterCount, yamlNodeCounterLimit int64
+
+// countYAMLNodes return error if YAML nodes exceed limit with aliases expanded
+func countYAMLNodes(data []byte, limit int64) (err error) {
+	yamlNodeCounterLock.Lock()
+	defer yamlNodeCounterLock.Unlock()
+	yamlNodeCounterCount, yamlNodeCounterLimit = 0, limit
+
+	return yaml.Unmarshal(data, &yamlNodeCounter{})
+}
+
+// yamlNodeCounter count YAML nodes with aliases expanded, decoding is
+// stopped when exceeding limit, so the expansion will not be completed
+type yamlNodeCounter struct{}
+
+// UnmarshalYAML implement yaml unmarshaler
+func (t *yamlNodeCounter) UnmarshalYAML(unmarshaler func(interface{}) error) (err error) {
+	yamlNodeCounterCount++
+	if yamlNodeCounterCount > yamlNodeCounterLimit {
+		return ErrorYAMLAliasExpansionExceeded1.New(nil, yamlNodeCounterLimit)
+	}
+
+	// decode children into counter only, never into interface{},
+	// otherwise aliases will be expanded without counting
+	mapping := map[yamlNodeCounter]yamlNodeCounter{}
+	if err = unmarshaler(&mapping); err == nil || ErrorYAMLAliasExpansionExceeded1.Match(err) {
+		return
+	}
+	sequence := []yamlNodeCounter{}
+	if err = unmarshaler(&sequence); err == nil || ErrorYAMLAliasExpansionExceeded1.Match(err) {
+		return
+	}
+	var scalar interface{}
+	return unmarshaler(&scalar)
+}
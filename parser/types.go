@@ -26,6 +26,7 @@ type PostProcessConfig interface {
 	Library() *Library
 	AnnotationUsage() map[string]bool
 	TraitUsage() map[string]bool
+	TypeUsage() map[string]bool
 }
 
 func newPostProcessConfig(
@@ -34,6 +35,7 @@ func newPostProcessConfig(
 	library *Library,
 	annotationUsage map[string]bool,
 	traitUsage map[string]bool,
+	typeUsage map[string]bool,
 ) PostProcessConfig {
 	if parser == nil {
 		parser = NewParser()
@@ -50,12 +52,16 @@ func newPostProcessConfig(
 	if traitUsage == nil {
 		traitUsage = map[string]bool{}
 	}
+	if typeUsage == nil {
+		typeUsage = map[string]bool{}
+	}
 	return postProcessConfigImpl{
 		dataParser:          parser,
 		dataRootDocument:    rootdoc,
 		dataLibrary:         library,
 		dataAnnotationUsage: annotationUsage,
 		dataTraitUsage:      traitUsage,
+		dataTypeUsage:       typeUsage,
 	}
 }
 
@@ -65,6 +71,7 @@ type postProcessConfigImpl struct {
 	dataLibrary         *Library
 	dataAnnotationUsage map[string]bool
 	dataTraitUsage      map[string]bool
+	dataTypeUsage       map[string]bool
 }
 
 func (t postProcessConfigImpl) Parser() Parser {
@@ -87,18 +94,8 @@ func (t postProcessConfigImpl) TraitUsage() map[string]bool {
 	return t.dataTraitUsage
 }
 
-type typoCheck map[string]*Value
-
-func (t typoCheck) IsEmpty() bool {
-	return len(t) == 0
-}
-
-func (t typoCheck) Names() []string {
-	names := []string{}
-	for name := range t {
-		names = append(names, name)
-	}
-	return names
+func (t postProcessConfigImpl) TypeUsage() map[string]bool {
+	return t.dataTypeUsage
 }
 
 // RAML built-in types
@@ -112,4 +109,9 @@ const (
 	TypeArray   = "array"
 	TypeFile    = "file"
 	TypeBinary  = "binary"
+
+	TypeDateOnly     = "date-only"
+	TypeTimeOnly     = "time-only"
+	TypeDateTimeOnly = "datetime-only"
+	TypeDateTime     = "datetime"
 )
@@ -0,0 +1,173 @@
+package parser
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type typoCheck map[string]*Value
+
+func (t typoCheck) IsEmpty() bool {
+	return len(t) == 0
+}
+
+// Names return sorted names of unknown keys
+func (t typoCheck) Names() []string {
+	names := []string{}
+	for name := range t {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// remove valid keys
+func (t typoCheck) remove(keys typoKeys) {
+	for name := range t {
+		if keys.match(name) {
+			delete(t, name)
+		}
+	}
+}
+
+// unmarshalTypoCheck unmarshal keys of YAML mapping which are not valid keys
+func unmarshalTypoCheck(unmarshaler func(interface{}) error, keys typoKeys) (check typoCheck, err error) {
+	check = typoCheck{}
+	if err = unmarshaler(&check); err != nil {
+		return nil, err
+	}
+	check.remove(keys)
+	return
+}
+
+var reflectTypeTypoCheck = reflect.TypeOf(typoCheck{})
+
+// typoKeys valid keys of YAML node, used to detect typo error and suggest
+// the closest valid key
+type typoKeys struct {
+	names    []string
+	patterns []*regexp.Regexp
+}
+
+// newTypoKeys return valid keys declared by yaml tags of struct types,
+// keys of regexp fields are matched by the regexp
+func newTypoKeys(types ...interface{}) typoKeys {
+	keys := typoKeys{}
+	for _, v := range types {
+		keys.add(reflect.TypeOf(v))
+	}
+	return keys
+}
+
+// withNames return keys with extra valid names
+func (t typoKeys) withNames(names ...string) typoKeys {
+	t.names = append(append([]string{}, t.names...), names...)
+	return t
+}
+
+func (t *typoKeys) add(typ reflect.Type) {
+	for i, n := 0, typ.NumField(); i < n; i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		if field.Type == reflectTypeTypoCheck {
+			continue
+		}
+
+		tag := field.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+		if idx := strings.Index(tag, ",regexp:"); idx >= 0 {
+			pattern := tag[idx+len(",regexp:"):]
+			t.patterns = append(t.patterns, regexp.MustCompile("^(?:"+pattern+")$"))
+			// pattern of literal alternatives, e.g. "(get|put)", are valid names
+			if regexpLiteralAlternatives.MatchString(pattern) {
+				t.names = append(t.names, strings.Split(strings.Trim(pattern, "()"), "|")...)
+			}
+			continue
+		}
+		if field.Anonymous || strings.Contains(tag, ",inline") {
+			if field.Type.Kind() == reflect.Struct {
+				t.add(field.Type)
+			}
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		t.names = append(t.names, name)
+	}
+}
+
+var regexpLiteralAlternatives = regexp.MustCompile(`^\(?\w+(\|\w+)*\)?$`)
+
+func (t typoKeys) match(key string) bool {
+	for _, name := range t.names {
+		if name == key {
+			return true
+		}
+	}
+	for _, pattern := range t.patterns {
+		if pattern.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// suggest return the closest valid name of key, empty if no name is close
+// enough, names are compared case-insensitively
+func (t typoKeys) suggest(key string) (suggestion string) {
+	lowerKey := strings.ToLower(key)
+	// allow about one edit per 3 characters, at least 2 edits
+	best := len(lowerKey) / 3
+	if best < 2 {
+		best = 2
+	}
+	best++
+	for _, name := range t.names {
+		if distance := editDistance(lowerKey, strings.ToLower(name)); distance < best {
+			best = distance
+			suggestion = name
+		}
+	}
+	return
+}
+
+// editDistance return Levenshtein distance between a and b
+func editDistance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func minInt(first int, others ...int) int {
+	result := first
+	for _, v := range others {
+		if v < result {
+			result = v
+		}
+	}
+	return result
+}
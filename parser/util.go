@@ -2,7 +2,6 @@ package parser
 
 import (
 	"bytes"
-	"io/ioutil"
 	"path/filepath"
 	"regexp"
 	"sort"
@@ -15,9 +14,16 @@ import (
 var jsonNull, _ = jsonex.Marshal(nil)
 
 // LoadRAMLFromDir load RAML data from directory, concat *.raml
+//
+// Deprecated: Parser.ParseFile parses directory as a project, which merges
+// *.raml files recursively with conflicts checked.
 func LoadRAMLFromDir(dirPath string) (ramlData []byte, err error) {
+	return loadRAMLFromDir(dirPath, &fileLoader{resolver: NewFileResolver("")})
+}
+
+func loadRAMLFromDir(dirPath string, loader *fileLoader) (ramlData []byte, err error) {
 	var filenames []string
-	if filenames, err = filepath.Glob(filepath.Join(dirPath, "*.raml")); err != nil {
+	if filenames, err = loader.glob(filepath.Join(dirPath, "*.raml")); err != nil {
 		return
 	}
 	sort.Strings(filenames)
@@ -25,7 +31,7 @@ func LoadRAMLFromDir(dirPath string) (ramlData []byte, err error) {
 	buffer := &bytes.Buffer{}
 	for _, filename := range filenames {
 		var filedata []byte
-		if filedata, err = ioutil.ReadFile(filename); err != nil {
+		if filedata, _, err = loader.readFile("", filename); err != nil {
 			return
 		}
 		if _, err = buffer.Write(filedata); err != nil {
@@ -0,0 +1,4 @@
+package parser
+
+// Version of go-raml-parser, cached RootDocument is invalidated if changed
+const Version = "1.0.7"
@@ -1,7 +1,10 @@
 package main
 
-import "github.com/tsaikd/KDGoLib/version"
+import (
+	"github.com/tsaikd/KDGoLib/version"
+	"github.com/tsaikd/go-raml-parser/parser"
+)
 
 func init() {
-	version.VERSION = "1.0.7"
+	version.VERSION = parser.Version
 }